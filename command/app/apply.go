@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"kube-helper/loader"
+	"kube-helper/naming"
 
 	"github.com/urfave/cli"
 )
@@ -10,6 +11,7 @@ import (
 func CmdApply(c *cli.Context) error {
 
 	kubernetesNamespace := getNamespace(c.Args().Get(0), c.Bool("production"))
+	loader.SetEnvEnvironment(kubernetesNamespace)
 
 	configContainer, err := configLoader.LoadConfigFromPath(c.String("config"))
 
@@ -23,14 +25,7 @@ func CmdApply(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	tag := "staging-" + kubernetesNamespace + "-latest"
-	if kubernetesNamespace == loader.StagingEnvironment {
-		tag = "staging-latest"
-	}
-
-	if kubernetesNamespace == loader.ProductionEnvironment {
-		tag = "latest"
-	}
+	tag := naming.LatestImageTag(kubernetesNamespace)
 
 	imagesService, err := serviceBuilder.GetImagesService()
 
@@ -2,7 +2,12 @@ package app
 
 import (
 	"fmt"
+	"strings"
 
+	"kube-helper/command"
+	"kube-helper/loader"
+	"kube-helper/model"
+	"kube-helper/naming"
 	"kube-helper/util"
 
 	"github.com/urfave/cli"
@@ -23,30 +28,80 @@ func CmdCleanUp(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	branches, err := branchLoader.LoadBranches(configContainer.Bitbucket)
+	if c.Bool("no-cache") {
+		configContainer.Branches.Cache.Disabled = true
+	}
+
+	branches, err := branchLoader.LoadBranches(configContainer)
 
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	usedNamespaces := []string{"kube-system", "default"}
+	var plan *model.Plan
+
+	if c.Bool("dry-run") {
+		plan = model.NewPlan("application cleanup")
+	}
+
+	cleanupGuard := cleanupGuardCreator(configContainer.Cleanup, c.Bool("force"), plan, cli.ErrWriter)
+
+	err = cleanupGuard.CheckBranches(branches)
 
-	for _, branchName := range branches {
-		usedNamespaces = append(usedNamespaces, getNamespace(branchName, false))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
 	}
 
+	prefix := configContainer.Namespace.Prefix
+	lookup := naming.NewLookup(branches)
+	protectedNamespaces := []string{"kube-system", "default", naming.Namespace(prefix, loader.StagingEnvironment)}
+
 	list, err := clientSet.CoreV1().Namespaces().List(v1.ListOptions{})
 
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
+	var namespacesForDeletion []string
+
+	// the percentage of the guard is based on the namespaces of this application only
+	applicationNamespaces := 0
+
 	for _, namespace := range list.Items {
-		if util.Contains(usedNamespaces, namespace.Name) {
+		if util.Contains(protectedNamespaces, namespace.Name) {
 			continue
 		}
 
-		appService, err := applicationServiceCreator(namespace.Name, configContainer)
+		// namespaces without the prefix do not belong to this application
+		if prefix != "" && !strings.HasPrefix(namespace.Name, prefix+"-") {
+			continue
+		}
+
+		applicationNamespaces++
+
+		if _, found := lookup.BranchForNamespace(prefix, namespace.Name); found {
+			continue
+		}
+
+		namespacesForDeletion = append(namespacesForDeletion, namespace.Name)
+	}
+
+	err = cleanupGuard.CheckDeletions(namespacesForDeletion, applicationNamespaces)
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	if plan != nil {
+		for _, namespace := range namespacesForDeletion {
+			plan.Add("Namespace", namespace)
+		}
+
+		return command.PrintPlan(c, writer, plan)
+	}
+
+	for _, namespace := range namespacesForDeletion {
+		appService, err := applicationServiceCreator(strings.TrimPrefix(namespace, prefix+"-"), configContainer)
 
 		if err != nil {
 			return cli.NewExitError(err.Error(), 1)
@@ -50,7 +50,7 @@ func TestCmdCleanupWithErrorForGetBranches(t *testing.T) {
 	oldBranchLoader := branchLoader
 	branchLoaderMock := new(mocks.BranchLoaderInterface)
 
-	branchLoaderMock.On("LoadBranches", config.Bitbucket).Return(nil, errors.New("explode"))
+	branchLoaderMock.On("LoadBranches", config).Return(nil, errors.New("explode"))
 
 	branchLoader = branchLoaderMock
 
@@ -106,7 +106,7 @@ func TestCmdCleanupWithErrorForGetNamespaces(t *testing.T) {
 	oldBranchLoader := branchLoader
 	branchLoaderMock := new(mocks.BranchLoaderInterface)
 
-	branchLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{}, nil)
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"master"}, nil)
 
 	branchLoader = branchLoaderMock
 
@@ -165,7 +165,7 @@ func TestCmdCleanupWithErrorForInitApplicationService(t *testing.T) {
 	oldBranchLoader := branchLoader
 	branchLoaderMock := new(mocks.BranchLoaderInterface)
 
-	branchLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{"test"}, nil)
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"test", "master"}, nil)
 
 	branchLoader = branchLoaderMock
 
@@ -229,7 +229,7 @@ func TestCmdCleanupWithErrorForDeleteNamespace(t *testing.T) {
 	oldBranchLoader := branchLoader
 	branchLoaderMock := new(mocks.BranchLoaderInterface)
 
-	branchLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{"test"}, nil)
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"test", "master"}, nil)
 
 	branchLoader = branchLoaderMock
 
@@ -248,3 +248,389 @@ func TestCmdCleanupWithErrorForDeleteNamespace(t *testing.T) {
 	assert.Equal(t, "explode", output)
 	assert.Empty(t, errOutput)
 }
+
+func TestCmdCleanupWithEmptyBranchList(t *testing.T) {
+	oldHandler := cli.OsExiter
+
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	config := loader.Config{
+		Cluster: loader.Cluster{
+			ProjectID: "test-project",
+			Zone:      "berlin",
+			ClusterID: "testing",
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	oldServiceBuilder := serviceBuilder
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetClientSet", config).Return(fake.NewSimpleClientset(), nil)
+
+	serviceBuilder = serviceBuilderMock
+
+	oldBranchLoader := branchLoader
+	branchLoaderMock := new(mocks.BranchLoaderInterface)
+
+	branchLoaderMock.On("LoadBranches", config).Return([]string{}, nil)
+
+	branchLoader = branchLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+		branchLoader = oldBranchLoader
+		serviceBuilder = oldServiceBuilder
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdCleanUp, []string{"cleanup", "-c", "never.yml"})
+	})
+
+	assert.Equal(t, "refusing to cleanup, the list of branches is empty (use --force to skip this check)\n", errOutput)
+	assert.Empty(t, output)
+}
+
+func TestCmdCleanupWithTooManyDeletions(t *testing.T) {
+	oldHandler := cli.OsExiter
+
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	config := loader.Config{
+		Cluster: loader.Cluster{
+			ProjectID: "test-project",
+			Zone:      "berlin",
+			ClusterID: "testing",
+		},
+		Cleanup: loader.Cleanup{
+			MaxDeletions: 1,
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	namespaceList := &v1.NamespaceList{
+		Items: []v1.Namespace{testNamespace("default"), testNamespace("foo"), testNamespace("bar")},
+	}
+
+	oldServiceBuilder := serviceBuilder
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetClientSet", config).Return(fake.NewSimpleClientset(namespaceList), nil)
+
+	serviceBuilder = serviceBuilderMock
+
+	oldBranchLoader := branchLoader
+	branchLoaderMock := new(mocks.BranchLoaderInterface)
+
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"master"}, nil)
+
+	branchLoader = branchLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+		branchLoader = oldBranchLoader
+		serviceBuilder = oldServiceBuilder
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdCleanUp, []string{"cleanup", "-c", "never.yml"})
+	})
+
+	assert.Equal(t, "refusing to cleanup, 2 deletions exceed the maximum of 1 per run (foo, bar) (use --force to skip this check)\n", errOutput)
+	assert.Empty(t, output)
+}
+
+func TestCmdCleanupDryRunWithTooManyDeletions(t *testing.T) {
+	oldHandler := cli.OsExiter
+
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	config := loader.Config{
+		Cluster: loader.Cluster{
+			ProjectID: "test-project",
+			Zone:      "berlin",
+			ClusterID: "testing",
+		},
+		Cleanup: loader.Cleanup{
+			MaxDeletions: 1,
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	namespaceList := &v1.NamespaceList{
+		Items: []v1.Namespace{testNamespace("default"), testNamespace("foo"), testNamespace("bar")},
+	}
+
+	oldServiceBuilder := serviceBuilder
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetClientSet", config).Return(fake.NewSimpleClientset(namespaceList), nil)
+
+	serviceBuilder = serviceBuilderMock
+
+	oldBranchLoader := branchLoader
+	branchLoaderMock := new(mocks.BranchLoaderInterface)
+
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"master"}, nil)
+
+	branchLoader = branchLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+		branchLoader = oldBranchLoader
+		serviceBuilder = oldServiceBuilder
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Fail(t, "the plan of a dry run should be printed without an exit code")
+	}
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdCleanUp, []string{"cleanup", "-c", "never.yml", "--dry-run"})
+	})
+
+	assert.Empty(t, errOutput)
+	assert.Equal(t, "Dry run of \"application cleanup\", nothing was removed.\nSafety check failed, the cleanup would be refused: 2 deletions exceed the maximum of 1 per run (foo, bar)\nNamespace \"foo\" would be removed.\nNamespace \"bar\" would be removed.\n", output)
+}
+
+func TestCmdCleanupWithForce(t *testing.T) {
+	oldHandler := cli.OsExiter
+
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	config := loader.Config{
+		Cluster: loader.Cluster{
+			ProjectID: "test-project",
+			Zone:      "berlin",
+			ClusterID: "testing",
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	namespaceList := &v1.NamespaceList{
+		Items: []v1.Namespace{testNamespace("default"), testNamespace("kube-system"), testNamespace("foobar")},
+	}
+
+	appService := new(mocks.ApplicationServiceInterface)
+	appService.On("DeleteByNamespace").Return(nil)
+
+	oldServiceBuilder := serviceBuilder
+	oldApplicationServiceCreator := applicationServiceCreator
+
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetClientSet", config).Return(fake.NewSimpleClientset(namespaceList), nil)
+
+	applicationServiceCreator = mockNewApplicationService(t, "foobar", config, appService, nil)
+
+	serviceBuilder = serviceBuilderMock
+
+	oldBranchLoader := branchLoader
+	branchLoaderMock := new(mocks.BranchLoaderInterface)
+
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"test"}, nil)
+
+	branchLoader = branchLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+		branchLoader = oldBranchLoader
+		serviceBuilder = oldServiceBuilder
+		applicationServiceCreator = oldApplicationServiceCreator
+	}()
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdCleanUp, []string{"cleanup", "-c", "never.yml", "--force"})
+	})
+
+	assert.Empty(t, output)
+	assert.Equal(t, "Safety check skipped because of --force: the default branch \"master\" is missing in the list of branches\n", errOutput)
+	appService.AssertExpectations(t)
+}
+
+func TestCmdCleanupWithPrefix(t *testing.T) {
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	config := loader.Config{
+		Namespace: loader.Namespace{Prefix: "app"},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	namespaceList := &v1.NamespaceList{
+		Items: []v1.Namespace{testNamespace("default"), testNamespace("other"), testNamespace("app-staging"), testNamespace("app-feature-jira-1-foo"), testNamespace("app-old")},
+	}
+
+	appService := new(mocks.ApplicationServiceInterface)
+	appService.On("DeleteByNamespace").Return(nil).Once()
+
+	oldServiceBuilder := serviceBuilder
+	oldApplicationServiceCreator := applicationServiceCreator
+
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetClientSet", config).Return(fake.NewSimpleClientset(namespaceList), nil)
+
+	applicationServiceCreator = mockNewApplicationService(t, "old", config, appService, nil)
+
+	serviceBuilder = serviceBuilderMock
+
+	oldBranchLoader := branchLoader
+	branchLoaderMock := new(mocks.BranchLoaderInterface)
+
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"master", "feature/JIRA-1_foo"}, nil)
+
+	branchLoader = branchLoaderMock
+
+	defer func() {
+		configLoader = oldConfigLoader
+		branchLoader = oldBranchLoader
+		serviceBuilder = oldServiceBuilder
+		applicationServiceCreator = oldApplicationServiceCreator
+	}()
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdCleanUp, []string{"cleanup", "-c", "never.yml"})
+	})
+
+	assert.Empty(t, output)
+	assert.Empty(t, errOutput)
+	appService.AssertExpectations(t)
+}
+
+func TestCmdCleanupWithTooHighDeletionPercentageForPrefix(t *testing.T) {
+	oldHandler := cli.OsExiter
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	config := loader.Config{
+		Namespace: loader.Namespace{Prefix: "app"},
+		Cleanup: loader.Cleanup{
+			MaxDeletionPercentage: 40,
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	namespaceList := &v1.NamespaceList{
+		Items: []v1.Namespace{
+			testNamespace("default"), testNamespace("kube-system"), testNamespace("other-1"), testNamespace("other-2"),
+			testNamespace("other-3"), testNamespace("app-staging"), testNamespace("app-feature-jira-1-foo"), testNamespace("app-old"),
+		},
+	}
+
+	oldServiceBuilder := serviceBuilder
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetClientSet", config).Return(fake.NewSimpleClientset(namespaceList), nil)
+
+	serviceBuilder = serviceBuilderMock
+
+	oldBranchLoader := branchLoader
+	branchLoaderMock := new(mocks.BranchLoaderInterface)
+
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"master", "feature/JIRA-1_foo"}, nil)
+
+	branchLoader = branchLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+		branchLoader = oldBranchLoader
+		serviceBuilder = oldServiceBuilder
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdCleanUp, []string{"cleanup", "-c", "never.yml"})
+	})
+
+	assert.Equal(t, "refusing to cleanup, 1 of 2 deletions exceed the maximum of 40% per run (app-old) (use --force to skip this check)\n", errOutput)
+	assert.Empty(t, output)
+}
+
+func TestCmdCleanupWithNoCache(t *testing.T) {
+	oldHandler := cli.OsExiter
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	config := loader.Config{
+		Cluster: loader.Cluster{
+			ProjectID: "test-project",
+			Zone:      "berlin",
+			ClusterID: "testing",
+		},
+		Branches: loader.Branches{
+			Cache: loader.BranchCache{TTL: "10m"},
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	oldServiceBuilder := serviceBuilder
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetClientSet", config).Return(fake.NewSimpleClientset(), nil)
+
+	serviceBuilder = serviceBuilderMock
+
+	oldBranchLoader := branchLoader
+	branchLoaderMock := new(mocks.BranchLoaderInterface)
+
+	uncachedConfig := config
+	uncachedConfig.Branches.Cache.Disabled = true
+
+	branchLoaderMock.On("LoadBranches", uncachedConfig).Return(nil, errors.New("explode"))
+
+	branchLoader = branchLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+		branchLoader = oldBranchLoader
+		serviceBuilder = oldServiceBuilder
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdCleanUp, []string{"cleanup", "-c", "never.yml", "--no-cache"})
+	})
+
+	assert.Equal(t, "explode\n", errOutput)
+	assert.Empty(t, output)
+	branchLoaderMock.AssertExpectations(t)
+}
@@ -2,19 +2,23 @@ package app
 
 import (
 	"fmt"
+	"kube-helper/loader"
 
 	"github.com/urfave/cli"
 )
 
 func CmdHasNamespace(c *cli.Context) error {
 
+	kubernetesNamespace := getNamespace(c.Args().Get(0), false)
+	loader.SetEnvEnvironment(kubernetesNamespace)
+
 	configContainer, err := configLoader.LoadConfigFromPath(c.String("config"))
 
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	appService, err := applicationServiceCreator(getNamespace(c.Args().Get(0), false), configContainer)
+	appService, err := applicationServiceCreator(kubernetesNamespace, configContainer)
 
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
@@ -5,9 +5,12 @@ import (
 	"os"
 
 	"kube-helper/loader"
+	"kube-helper/naming"
 	"kube-helper/service/app"
 	"kube-helper/service/builder"
-	"strings"
+	"kube-helper/service/guard"
+
+	"github.com/spf13/afero"
 )
 
 var writer io.Writer = os.Stdout
@@ -15,9 +18,12 @@ var serviceBuilder = builder.NewServiceBuilder()
 var configLoader = loader.NewConfigLoader()
 var branchLoader loader.BranchLoaderInterface = new(loader.BranchLoader)
 var applicationServiceCreator = app.NewApplicationService
+var cleanupGuardCreator = guard.NewCleanupGuard
+var applicationRenderer = app.Render
+var fileSystem = afero.NewOsFs()
 
 func getNamespace(branchName string, isProdution bool) string {
-	namespace := strings.ToLower(branchName)
+	namespace := naming.Namespace("", branchName)
 
 	if isProdution {
 		return loader.ProductionEnvironment
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"kube-helper/loader"
+	"kube-helper/service/app"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/afero"
+	"github.com/urfave/cli"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const renderedFilenamePattern = "%03d-%s-%s.yaml"
+
+// CmdRender prints the manifests of the config as yaml stream after the placeholders, images and namespace are applied
+func CmdRender(c *cli.Context) error {
+
+	kubernetesNamespace := getNamespace(c.Args().Get(0), c.Bool("production"))
+	loader.SetEnvEnvironment(kubernetesNamespace)
+
+	configContainer, err := configLoader.LoadConfigFromPath(c.String("config"))
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	options := app.RenderOptions{
+		Offline:           c.Bool("offline"),
+		AllowUnknownKinds: c.Bool("allow-unknown-kinds"),
+	}
+
+	if options.AllowUnknownKinds && !options.Offline {
+		return cli.NewExitError("--allow-unknown-kinds can only be used with --offline", 1)
+	}
+
+	outputDir := c.String("output-dir")
+
+	if outputDir != "" {
+		err = fileSystem.MkdirAll(outputDir, 0755)
+
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+	}
+
+	index := 0
+
+	err = applicationRenderer(kubernetesNamespace, configContainer, options, func(object runtime.Object) error {
+		index++
+
+		content, err := json.Marshal(object)
+
+		if err != nil {
+			return err
+		}
+
+		content, err = yaml.JSONToYAML(content)
+
+		if err != nil {
+			return err
+		}
+
+		if outputDir == "" {
+			if index > 1 {
+				fmt.Fprintln(writer, "---")
+			}
+
+			fmt.Fprint(writer, string(content))
+
+			return nil
+		}
+
+		accessor, err := meta.Accessor(object)
+
+		if err != nil {
+			return err
+		}
+
+		kind := strings.ToLower(object.GetObjectKind().GroupVersionKind().Kind)
+		path := filepath.Join(outputDir, fmt.Sprintf(renderedFilenamePattern, index, kind, accessor.GetName()))
+
+		err = afero.WriteFile(fileSystem, path, content, 0644)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(writer, "%s \"%s\" was written to %s.\n", object.GetObjectKind().GroupVersionKind().Kind, accessor.GetName(), path)
+
+		return nil
+	})
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
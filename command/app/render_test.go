@@ -0,0 +1,213 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"kube-helper/command"
+	"kube-helper/loader"
+	"kube-helper/mocks"
+	"kube-helper/service/app"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+	coreV1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func getRenderTestObjects() []runtime.Object {
+	secret := &coreV1.Secret{ObjectMeta: meta_v1.ObjectMeta{Name: "secret", Namespace: "foobar"}, Data: map[string][]byte{"password": []byte("s3cr3t")}}
+	secret.Kind = "Secret"
+	secret.APIVersion = "v1"
+
+	configMap := &coreV1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: "config", Namespace: "foobar"}, Data: map[string]string{"key": "value"}}
+	configMap.Kind = "ConfigMap"
+	configMap.APIVersion = "v1"
+
+	return []runtime.Object{secret, configMap}
+}
+
+func mockApplicationRenderer(t *testing.T, expectedConfig loader.Config, expectedOptions app.RenderOptions, objects []runtime.Object, err error) func(namespace string, config loader.Config, options app.RenderOptions, renderFunc app.RenderFunc) error {
+	return func(namespace string, config loader.Config, options app.RenderOptions, renderFunc app.RenderFunc) error {
+		assert.Equal(t, "foobar", namespace)
+		assert.Equal(t, expectedConfig, config)
+		assert.Equal(t, expectedOptions, options)
+
+		for _, object := range objects {
+			renderErr := renderFunc(object)
+
+			if renderErr != nil {
+				return renderErr
+			}
+		}
+
+		return err
+	}
+}
+
+func TestCmdRenderWithWrongConf(t *testing.T) {
+	helperTestCmdHasWrongConfigReturned(t, CmdRender, []string{"render", "-c", "never.yml", "foobar"})
+}
+
+func TestCmdRender(t *testing.T) {
+	oldConfigLoader := configLoader
+	oldApplicationRenderer := applicationRenderer
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	defer func() {
+		configLoader = oldConfigLoader
+		applicationRenderer = oldApplicationRenderer
+	}()
+
+	config := loader.Config{KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"}}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	applicationRenderer = mockApplicationRenderer(t, config, app.RenderOptions{}, getRenderTestObjects(), nil)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdRender, []string{"render", "-c", "never.yml", "foobar"})
+	})
+
+	assert.Empty(t, errOutput)
+	assert.Equal(t, `apiVersion: v1
+data:
+  password: czNjcjN0
+kind: Secret
+metadata:
+  creationTimestamp: null
+  name: secret
+  namespace: foobar
+---
+apiVersion: v1
+data:
+  key: value
+kind: ConfigMap
+metadata:
+  creationTimestamp: null
+  name: config
+  namespace: foobar
+`, output)
+}
+
+func TestCmdRenderToDirectory(t *testing.T) {
+	oldConfigLoader := configLoader
+	oldApplicationRenderer := applicationRenderer
+	oldFileSystem := fileSystem
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+	fileSystem = afero.NewMemMapFs()
+
+	defer func() {
+		configLoader = oldConfigLoader
+		applicationRenderer = oldApplicationRenderer
+		fileSystem = oldFileSystem
+	}()
+
+	config := loader.Config{KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"}}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	applicationRenderer = mockApplicationRenderer(t, config, app.RenderOptions{}, getRenderTestObjects(), nil)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdRender, []string{"render", "-c", "never.yml", "-d", "rendered", "foobar"})
+	})
+
+	assert.Empty(t, errOutput)
+	assert.Equal(t, "Secret \"secret\" was written to rendered/001-secret-secret.yaml.\nConfigMap \"config\" was written to rendered/002-configmap-config.yaml.\n", output)
+
+	content, err := afero.ReadFile(fileSystem, "rendered/002-configmap-config.yaml")
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "kind: ConfigMap\n")
+}
+
+func TestCmdRenderWithErrorForRenderer(t *testing.T) {
+	oldHandler := cli.OsExiter
+	oldConfigLoader := configLoader
+	oldApplicationRenderer := applicationRenderer
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+		applicationRenderer = oldApplicationRenderer
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	config := loader.Config{}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	applicationRenderer = mockApplicationRenderer(t, config, app.RenderOptions{}, nil, errors.New("kind Pod is not supported"))
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdRender, []string{"render", "-c", "never.yml", "foobar"})
+	})
+
+	assert.Empty(t, output)
+	assert.Equal(t, "kind Pod is not supported\n", errOutput)
+}
+
+func TestCmdRenderOffline(t *testing.T) {
+	oldConfigLoader := configLoader
+	oldApplicationRenderer := applicationRenderer
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	defer func() {
+		configLoader = oldConfigLoader
+		applicationRenderer = oldApplicationRenderer
+	}()
+
+	config := loader.Config{KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"}}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	applicationRenderer = mockApplicationRenderer(t, config, app.RenderOptions{Offline: true, AllowUnknownKinds: true}, getRenderTestObjects()[:1], nil)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdRender, []string{"render", "-c", "never.yml", "--offline", "--allow-unknown-kinds", "foobar"})
+	})
+
+	assert.Empty(t, errOutput)
+	assert.Contains(t, output, "kind: Secret\n")
+}
+
+func TestCmdRenderWithUnknownKindsWithoutOffline(t *testing.T) {
+	oldHandler := cli.OsExiter
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(loader.Config{}, nil)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdRender, []string{"render", "-c", "never.yml", "--allow-unknown-kinds", "foobar"})
+	})
+
+	assert.Empty(t, output)
+	assert.Equal(t, "--allow-unknown-kinds can only be used with --offline\n", errOutput)
+}
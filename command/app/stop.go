@@ -1,12 +1,16 @@
 package app
 
 import (
+	"kube-helper/loader"
+
 	"github.com/urfave/cli"
 )
 
 func CmdShutdown(c *cli.Context) error {
 
 	kubernetesNamespace := getNamespace(c.Args().Get(0), c.Bool("production"))
+	loader.SetEnvEnvironment(kubernetesNamespace)
+
 	configContainer, err := configLoader.LoadConfigFromPath(c.String("config"))
 
 	if err != nil {
@@ -5,6 +5,9 @@ import (
 
 	"strings"
 
+	"kube-helper/command"
+	"kube-helper/model"
+
 	"github.com/urfave/cli"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -29,12 +32,27 @@ func CmdShutdownAll(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
+	var namespacesForDeletion []string
+
 	for _, namespace := range list.Items {
 		if strings.HasPrefix(namespace.Name, "kube") || namespace.Name == "default" || !strings.HasPrefix(namespace.Name, configContainer.Namespace.Prefix) {
 			continue
 		}
 
-		appService, err := applicationServiceCreator(strings.TrimPrefix(namespace.Name, configContainer.Namespace.Prefix+"-"), configContainer)
+		namespacesForDeletion = append(namespacesForDeletion, namespace.Name)
+	}
+
+	if c.Bool("dry-run") {
+		plan := model.NewPlan("application shutdown-all")
+		for _, namespace := range namespacesForDeletion {
+			plan.Add("Namespace", namespace)
+		}
+
+		return command.PrintPlan(c, writer, plan)
+	}
+
+	for _, namespace := range namespacesForDeletion {
+		appService, err := applicationServiceCreator(strings.TrimPrefix(namespace, configContainer.Namespace.Prefix+"-"), configContainer)
 
 		if err != nil {
 			return cli.NewExitError(err.Error(), 1)
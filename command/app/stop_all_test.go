@@ -7,6 +7,7 @@ import (
 	"kube-helper/command"
 	"kube-helper/loader"
 	"kube-helper/mocks"
+	"kube-helper/service/app"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli"
@@ -234,3 +235,53 @@ func TestCmdShutdownAllWithErrorDeleteNamespaceWithPrefix(t *testing.T) {
 	assert.Equal(t, "explode", output)
 	assert.Empty(t, errOutput)
 }
+
+func TestCmdShutdownAllWithDryRun(t *testing.T) {
+
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	config := loader.Config{
+		Cluster: loader.Cluster{
+			ProjectID: "test-project",
+			Zone:      "berlin",
+			ClusterID: "testing",
+		},
+		Namespace: loader.Namespace{
+			Prefix: "prefix",
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	namespaceList := &v1.NamespaceList{
+		Items: []v1.Namespace{testNamespace("default"), testNamespace("kube-system"), testNamespace("prefix-foobar"), testNamespace("other")},
+	}
+
+	oldServiceBuilder := serviceBuilder
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetClientSet", config).Return(fake.NewSimpleClientset(namespaceList), nil)
+
+	serviceBuilder = serviceBuilderMock
+
+	oldApplicationServiceCreator := applicationServiceCreator
+	applicationServiceCreator = func(namespace string, config loader.Config) (app.ApplicationServiceInterface, error) {
+		t.Error("no application service should be created on a dry run")
+		return nil, nil
+	}
+
+	defer func() {
+		configLoader = oldConfigLoader
+		serviceBuilder = oldServiceBuilder
+		applicationServiceCreator = oldApplicationServiceCreator
+	}()
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdShutdownAll, []string{"shutdown-all", "-c", "never.yml", "--dry-run"})
+	})
+
+	assert.Equal(t, "Dry run of \"application shutdown-all\", nothing was removed.\nNamespace \"prefix-foobar\" would be removed.\n", output)
+	assert.Empty(t, errOutput)
+}
@@ -0,0 +1,11 @@
+package config
+
+import (
+	"io"
+	"os"
+
+	"kube-helper/loader"
+)
+
+var writer io.Writer = os.Stdout
+var configLoader = loader.NewConfigLoader()
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+
+	"kube-helper/loader"
+
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+const maskedValue = "********"
+
+// CmdShow prints the config with the overlay of the environment merged over the base config
+func CmdShow(c *cli.Context) error {
+	loader.SetEnvEnvironment(c.String("env"))
+
+	configContainer, err := configLoader.LoadConfigFromPath(c.String("config"))
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	content, err := yaml.Marshal(maskCredentials(configContainer))
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	fmt.Fprint(writer, string(content))
+
+	return nil
+}
+
+// maskCredentials hides the secrets of the branch providers, the output of the command ends up in build logs
+func maskCredentials(config loader.Config) loader.Config {
+	mask := func(value *string) {
+		if *value != "" {
+			*value = maskedValue
+		}
+	}
+
+	mask(&config.Bitbucket.ClientSecret)
+	mask(&config.GitHub.Token)
+	mask(&config.GitLab.Token)
+
+	return config
+}
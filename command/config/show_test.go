@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"kube-helper/command"
+	"kube-helper/loader"
+	"kube-helper/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func captureOutput(f func()) (string, string) {
+	oldWriter := writer
+	oldErrWriter := cli.ErrWriter
+	var buf bytes.Buffer
+	var errBuf bytes.Buffer
+	defer func() {
+		writer = oldWriter
+		cli.ErrWriter = oldErrWriter
+	}()
+	writer = &buf
+	cli.ErrWriter = &errBuf
+	f()
+	return buf.String(), errBuf.String()
+}
+
+func TestCmdShow(t *testing.T) {
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	defer func() {
+		configLoader = oldConfigLoader
+		loader.SetEnvEnvironment("")
+	}()
+
+	config := loader.Config{
+		Cluster: loader.Cluster{
+			Type:      "gcp",
+			ProjectID: "production-project",
+			ClusterID: "testing",
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdShow, []string{"show", "-c", "never.yml", "--env", "production"})
+	})
+
+	assert.Empty(t, errOutput)
+	assert.Contains(t, output, "cluster:\n  type: gcp\n  project_id: production-project\n  cluster_id: testing\n")
+}
+
+func TestCmdShowMasksCredentials(t *testing.T) {
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	defer func() {
+		configLoader = oldConfigLoader
+	}()
+
+	config := loader.Config{
+		Bitbucket: loader.Bitbucket{
+			ClientID:     "client-id",
+			ClientSecret: "bitbucket-secret",
+		},
+		GitHub: loader.GitHub{
+			Token: "github-token",
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdShow, []string{"show", "-c", "never.yml"})
+	})
+
+	assert.Empty(t, errOutput)
+	assert.Contains(t, output, "client_id: client-id\n  client_secret: '********'\n")
+	assert.Contains(t, output, "github:\n  token: '********'\n")
+	assert.Contains(t, output, "gitlab:\n  token: \"\"\n")
+	assert.NotContains(t, output, "bitbucket-secret")
+	assert.NotContains(t, output, "github-token")
+	assert.Equal(t, "bitbucket-secret", config.Bitbucket.ClientSecret)
+}
+
+func TestCmdShowWithWrongConfig(t *testing.T) {
+	oldHandler := cli.OsExiter
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(loader.Config{}, errors.New("explode"))
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdShow, []string{"show", "-c", "never.yml"})
+	})
+
+	assert.Empty(t, output)
+	assert.Equal(t, "explode\n", errOutput)
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+
+	"kube-helper/loader"
+
+	"github.com/urfave/cli"
+)
+
+// CmdValidate checks the config with the overlay of an environment and prints every problem with its position
+func CmdValidate(c *cli.Context) error {
+	loader.SetEnvEnvironment(c.String("env"))
+
+	problems, err := configLoader.ValidateConfigFromPath(c.String("config"))
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	if len(problems) == 0 {
+		fmt.Fprintf(writer, "Config %s is valid.\n", c.String("config"))
+		return nil
+	}
+
+	for _, problem := range problems {
+		if problem.Line == 0 {
+			fmt.Fprintf(writer, "%s: %s\n", c.String("config"), problem.Message)
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s:%s\n", c.String("config"), problem)
+	}
+
+	return cli.NewExitError(fmt.Sprintf("config %s has %d problem(s)", c.String("config"), len(problems)), 1)
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"kube-helper/command"
+	"kube-helper/loader"
+	"kube-helper/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func TestCmdValidate(t *testing.T) {
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	defer func() {
+		configLoader = oldConfigLoader
+	}()
+
+	configLoaderMock.On("ValidateConfigFromPath", "never.yml").Return(nil, nil)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdValidate, []string{"validate", "-c", "never.yml"})
+	})
+
+	assert.Empty(t, errOutput)
+	assert.Equal(t, "Config never.yml is valid.\n", output)
+}
+
+func TestCmdValidateWithProblems(t *testing.T) {
+	oldHandler := cli.OsExiter
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+		loader.SetEnvEnvironment("")
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	configLoaderMock.On("ValidateConfigFromPath", "never.yml").Return([]loader.ConfigProblem{
+		{Path: "cluster.regoin", Line: 5, Column: 3, Message: "cluster.regoin is not a known key"},
+		{Path: "github.owner", Message: "github.owner is required for the branch provider github"},
+	}, nil)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdValidate, []string{"validate", "-c", "never.yml", "--env", "production"})
+	})
+
+	assert.Equal(t, "never.yml:5:3: cluster.regoin is not a known key\nnever.yml: github.owner is required for the branch provider github\n", output)
+	assert.Equal(t, "config never.yml has 2 problem(s)\n", errOutput)
+}
+
+func TestCmdValidateWithErrorForReading(t *testing.T) {
+	oldHandler := cli.OsExiter
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	defer func() {
+		cli.OsExiter = oldHandler
+		configLoader = oldConfigLoader
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	configLoaderMock.On("ValidateConfigFromPath", "never.yml").Return(nil, errors.New("explode"))
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdValidate, []string{"validate", "-c", "never.yml"})
+	})
+
+	assert.Empty(t, output)
+	assert.Equal(t, "explode\n", errOutput)
+}
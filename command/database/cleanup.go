@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"strings"
 
-	"kube-helper/util"
+	"kube-helper/command"
+	"kube-helper/model"
+	"kube-helper/naming"
 
 	"github.com/urfave/cli"
 	"google.golang.org/api/sqladmin/v1beta4"
@@ -24,36 +26,78 @@ func CmdCleanup(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	branches, err := branchLoader.LoadBranches(configContainer.Bitbucket)
+	if c.Bool("no-cache") {
+		configContainer.Branches.Cache.Disabled = true
+	}
+
+	branches, err := branchLoader.LoadBranches(configContainer)
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	var plan *model.Plan
+
+	if c.Bool("dry-run") {
+		plan = model.NewPlan("database cleanup")
+	}
+
+	cleanupGuard := cleanupGuardCreator(configContainer.Cleanup, c.Bool("force"), plan, cli.ErrWriter)
+
+	err = cleanupGuard.CheckBranches(branches)
 
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
+
 	databases, err := getDatabases(sqlService, configContainer.Cluster.ProjectID, configContainer.Database.Instance)
 
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
+	lookup := naming.NewLookup(branches)
+
+	var branchDatabases []string
+	var databasesForDeletion []string
+
 	for _, database := range databases {
 		if !strings.HasPrefix(database, configContainer.Database.PrefixBranchDatabase) {
 			continue
 		}
 
-		branch := strings.TrimPrefix(database, configContainer.Database.PrefixBranchDatabase)
+		branchDatabases = append(branchDatabases, database)
 
-		if util.Contains(branches, branch) == false {
-			operation, err := sqlService.Databases.Delete(configContainer.Cluster.ProjectID, configContainer.Database.Instance, database).Do()
-			if err != nil {
-				return cli.NewExitError(err.Error(), 1)
-			}
-			err = waitForOperationToFinish(sqlService, operation, configContainer.Cluster.ProjectID, "delete of database")
-			if err != nil {
-				return cli.NewExitError(err.Error(), 1)
-			}
+		if _, found := lookup.BranchForDatabase(configContainer.Database.PrefixBranchDatabase, database); !found {
+			databasesForDeletion = append(databasesForDeletion, database)
+		}
+	}
+
+	err = cleanupGuard.CheckDeletions(databasesForDeletion, len(branchDatabases))
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
 
-			fmt.Fprintf(writer, "Removed database %s", database)
+	if plan != nil {
+		for _, database := range databasesForDeletion {
+			plan.Add("Database", database)
 		}
+
+		return command.PrintPlan(c, writer, plan)
+	}
+
+	for _, database := range databasesForDeletion {
+		operation, err := sqlService.Databases.Delete(configContainer.Cluster.ProjectID, configContainer.Database.Instance, database).Do()
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		err = waitForOperationToFinish(sqlService, operation, configContainer.Cluster.ProjectID, "delete of database")
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+
+		fmt.Fprintf(writer, "Removed database %s", database)
 	}
 
 	return nil
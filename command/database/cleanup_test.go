@@ -102,7 +102,7 @@ func TestCmdCleanupWithFailureLoadBranches(t *testing.T) {
 	branchLoaderMock := new(mocks.BranchLoaderInterface)
 	branchLoader = branchLoaderMock
 
-	branchLoaderMock.On("LoadBranches", config.Bitbucket).Return(nil, errors.New("explode"))
+	branchLoaderMock.On("LoadBranches", config).Return(nil, errors.New("explode"))
 
 	defer func() {
 		cli.OsExiter = oldHandler
@@ -153,7 +153,7 @@ func TestCmdCleanupWithFailureLoadDatabases(t *testing.T) {
 	branchLoaderMock := new(mocks.BranchLoaderInterface)
 	branchLoader = branchLoaderMock
 
-	branchLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{"master", "ets-123"}, nil)
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"master", "ets-123"}, nil)
 
 	defer func() {
 		cli.OsExiter = oldHandler
@@ -214,7 +214,7 @@ func TestCmdCleanupWithFailureForDelete(t *testing.T) {
 	branchLoaderMock := new(mocks.BranchLoaderInterface)
 	branchLoader = branchLoaderMock
 
-	branchLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{"master", "ets-123"}, nil)
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"master", "ets-123"}, nil)
 
 	defer func() {
 		cli.OsExiter = oldHandler
@@ -310,7 +310,7 @@ func TestCmdCleanupWithFailureDuringWait(t *testing.T) {
 	branchLoaderMock := new(mocks.BranchLoaderInterface)
 	branchLoader = branchLoaderMock
 
-	branchLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{"master", "ets-123"}, nil)
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"master", "ets-123"}, nil)
 
 	defer func() {
 		cli.OsExiter = oldHandler
@@ -434,7 +434,7 @@ func TestCmdCleanup(t *testing.T) {
 	branchLoaderMock := new(mocks.BranchLoaderInterface)
 	branchLoader = branchLoaderMock
 
-	branchLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{"master", "ets-123"}, nil)
+	branchLoaderMock.On("LoadBranches", config).Return([]string{"master", "ets-123"}, nil)
 
 	defer func() {
 		cli.OsExiter = oldHandler
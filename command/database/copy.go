@@ -5,6 +5,7 @@ import (
 	"compress/gzip"
 	"fmt"
 	"kube-helper/loader"
+	"kube-helper/naming"
 	"kube-helper/util"
 	"strings"
 
@@ -32,12 +33,8 @@ func getDatabaseName(databaseConfig loader.Database, branchName string) string {
 	if branchName == "master" {
 		return databaseConfig.BaseName
 	}
-	databaseName := databaseConfig.PrefixBranchDatabase + branchName
-	length := 60
-	if length > len(databaseName) {
-		length = len(databaseName)
-	}
-	return databaseName[:length]
+
+	return naming.Database(databaseConfig.PrefixBranchDatabase, branchName)
 }
 
 func CopyDatabaseByBranchName(branchName string, configContainer loader.Config) error {
@@ -7,6 +7,7 @@ import (
 	"kube-helper/command"
 	"kube-helper/loader"
 	"kube-helper/mocks"
+	"kube-helper/naming"
 
 	"bufio"
 	"compress/gzip"
@@ -141,6 +142,16 @@ func TestCmdCommandWithExistingDatabase(t *testing.T) {
 
 }
 
+func TestGetDatabaseNameIsFoundByTheCleanup(t *testing.T) {
+	databaseConfig := loader.Database{BaseName: "foobar", PrefixBranchDatabase: "branch_"}
+	lookup := naming.NewLookup([]string{"master", "feature-x"})
+
+	branchName, found := lookup.BranchForDatabase(databaseConfig.PrefixBranchDatabase, getDatabaseName(databaseConfig, "Feature-X"))
+
+	assert.True(t, found)
+	assert.Equal(t, "feature-x", branchName)
+}
+
 func TestCmdCommandWithFailureToGetStorageService(t *testing.T) {
 	oldHandler := cli.OsExiter
 
@@ -9,6 +9,7 @@ import (
 
 	"kube-helper/loader"
 	"kube-helper/service/builder"
+	"kube-helper/service/guard"
 
 	"google.golang.org/api/sqladmin/v1beta4"
 	utilClock "k8s.io/apimachinery/pkg/util/clock"
@@ -19,6 +20,7 @@ var serviceBuilder = builder.NewServiceBuilder()
 var configLoader = loader.NewConfigLoader()
 var branchLoader loader.BranchLoaderInterface = new(loader.BranchLoader)
 var clock utilClock.Clock = new(utilClock.RealClock)
+var cleanupGuardCreator = guard.NewCleanupGuard
 
 func waitForOperationToFinish(sqlService *sqladmin.Service, operation *sqladmin.Operation, projectID string, operationType string) error {
 	var err error
@@ -30,6 +30,42 @@ func RunTestCommand(Action interface{}, arguments []string) {
 				Name: "production, p",
 				Usage: "update production",
 			},
+			cli.BoolFlag{
+				Name:  "force",
+				Usage: "skip the safety checks",
+			},
+			cli.BoolFlag{
+				Name:  "no-cache",
+				Usage: "skip the branch cache",
+			},
+			cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "print what would be removed",
+			},
+			cli.StringFlag{
+				Name:  "output, o",
+				Usage: "format of the dry run plan",
+			},
+			cli.StringFlag{
+				Name:  "key-file",
+				Usage: "key for the encryption of secrets",
+			},
+			cli.StringFlag{
+				Name:  "env",
+				Usage: "environment of the config",
+			},
+			cli.StringFlag{
+				Name:  "output-dir, d",
+				Usage: "directory for the rendered manifests",
+			},
+			cli.BoolFlag{
+				Name:  "offline",
+				Usage: "render without the cluster",
+			},
+			cli.BoolFlag{
+				Name:  "allow-unknown-kinds",
+				Usage: "render unknown kinds",
+			},
 		},
 	}
 
@@ -0,0 +1,20 @@
+package command
+
+import (
+	"io"
+
+	"kube-helper/model"
+
+	"github.com/urfave/cli"
+)
+
+// PrintPlan prints the plan of a dry run in the format of the output flag
+func PrintPlan(c *cli.Context, writer io.Writer, plan *model.Plan) error {
+	err := plan.Print(writer, c.String("output"))
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
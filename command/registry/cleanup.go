@@ -6,13 +6,15 @@ import (
 	"strings"
 
 	"kube-helper/loader"
-	"kube-helper/util"
 
 	"fmt"
+	"kube-helper/command"
 	"kube-helper/model"
+	"kube-helper/naming"
 	"regexp"
 
 	"kube-helper/service/builder"
+	"kube-helper/service/guard"
 
 	"github.com/urfave/cli"
 )
@@ -20,11 +22,12 @@ import (
 var configLoader = loader.NewConfigLoader()
 var branchLoader loader.BranchLoaderInterface = new(loader.BranchLoader)
 var serviceBuilder = builder.NewServiceBuilder()
+var cleanupGuardCreator = guard.NewCleanupGuard
 
 var writer io.Writer = os.Stdout
 
 // CmdCleanup cleans the project related registry in gcp
-// Remove all images which are not related anymore to a branch in bitbucket
+// Remove all images which are not related anymore to a branch of the repository
 func CmdCleanup(c *cli.Context) error {
 
 	configContainer, err := configLoader.LoadConfigFromPath(c.String("config"))
@@ -44,12 +47,32 @@ func CmdCleanup(c *cli.Context) error {
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
-	branches, err := branchLoader.LoadBranches(configContainer.Bitbucket)
+	if c.Bool("no-cache") {
+		configContainer.Branches.Cache.Disabled = true
+	}
+
+	branches, err := branchLoader.LoadBranches(configContainer)
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	var plan *model.Plan
+
+	if c.Bool("dry-run") {
+		plan = model.NewPlan("registry cleanup")
+	}
+
+	cleanupGuard := cleanupGuardCreator(configContainer.Cleanup, c.Bool("force"), plan, cli.ErrWriter)
+
+	err = cleanupGuard.CheckBranches(branches)
 
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
+	lookup := naming.NewLookup(branches)
+
 	manifestsForDeletion := map[string]model.Manifest{}
 
 	latestTagFound := false
@@ -79,10 +102,8 @@ func CmdCleanup(c *cli.Context) error {
 
 			if strings.HasSuffix(tag, "latest") {
 
-				branchName := strings.TrimSuffix(strings.TrimPrefix(tag, "staging-"), "-latest")
-
 				//do not cleanup if branch exists
-				if util.Contains(branches, branchName) {
+				if _, found := lookup.BranchForImageTag(tag); found {
 					cleanup = false
 					break
 				}
@@ -94,8 +115,30 @@ func CmdCleanup(c *cli.Context) error {
 		}
 	}
 
-	for manifestID, manifest := range manifestsForDeletion {
-		for _, tag := range manifest.Tags {
+	var manifestIDs []string
+
+	for _, manifestPair := range manifests.SortedManifests {
+		if _, ok := manifestsForDeletion[manifestPair.Key]; ok {
+			manifestIDs = append(manifestIDs, manifestPair.Key)
+		}
+	}
+
+	err = cleanupGuard.CheckDeletions(manifestIDs, len(manifests.SortedManifests))
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	if plan != nil {
+		for _, manifestID := range manifestIDs {
+			plan.Add("Image", manifestID, manifestsForDeletion[manifestID].Tags...)
+		}
+
+		return command.PrintPlan(c, writer, plan)
+	}
+
+	for _, manifestID := range manifestIDs {
+		for _, tag := range manifestsForDeletion[manifestID].Tags {
 			err = imagesService.Untag(configContainer.Cleanup, tag)
 
 			if err != nil {
@@ -163,7 +163,7 @@ func TestCmdCleanupWithErrorOnBranchesCall(t *testing.T) {
 	oldBranchLoader := branchLoader
 	branchLoader = branchesLoaderMock
 
-	branchesLoaderMock.On("LoadBranches", config.Bitbucket).Return(nil, errors.New("explode"))
+	branchesLoaderMock.On("LoadBranches", config).Return(nil, errors.New("explode"))
 
 	defer func() {
 		cli.OsExiter = oldHandler
@@ -229,7 +229,7 @@ func TestCmdCleanupWithErrorOnUntagCall(t *testing.T) {
 
 	branchLoader = branchesLoaderMock
 
-	branchesLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{"branch-1", "master"}, nil)
+	branchesLoaderMock.On("LoadBranches", config).Return([]string{"branch-1", "master"}, nil)
 
 	defer func() {
 		cli.OsExiter = oldHandler
@@ -298,7 +298,7 @@ func TestCmdCleanupWithErrorOnDeleteManifestCall(t *testing.T) {
 
 	branchLoader = branchesLoaderMock
 
-	branchesLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{"branch-1", "master"}, nil)
+	branchesLoaderMock.On("LoadBranches", config).Return([]string{"branch-1", "master"}, nil)
 
 	defer func() {
 		cli.OsExiter = oldHandler
@@ -421,7 +421,7 @@ func TestCmdCleanupOnlyStaging(t *testing.T) {
 
 	branchLoader = branchesLoaderMock
 
-	branchesLoaderMock.On("LoadBranches", config.Bitbucket).Return([]string{"branch-1", "master"}, nil)
+	branchesLoaderMock.On("LoadBranches", config).Return([]string{"branch-1", "master"}, nil)
 
 	defer func() {
 		cli.OsExiter = oldHandler
@@ -464,3 +464,74 @@ func captureOutput(f func()) (string, string) {
 	f()
 	return buf.String(), errBuf.String()
 }
+
+func TestCmdCleanupWithDryRun(t *testing.T) {
+	oldConfigLoader := configLoader
+	configLoaderMock := new(mocks.ConfigLoader)
+
+	configLoader = configLoaderMock
+
+	config := loader.Config{
+		Cleanup: loader.Cleanup{
+			ImagePath: "area.local/projectName/image-name",
+		},
+	}
+
+	configLoaderMock.On("LoadConfigFromPath", "never.yml").Return(config, nil)
+
+	oldServiceBuilder := serviceBuilder
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+
+	imagesLoaderMock := new(mocks.ImagesInterface)
+
+	serviceBuilder = serviceBuilderMock
+
+	serviceBuilderMock.On("GetImagesService").Return(imagesLoaderMock, nil)
+
+	collection := &model.TagCollection{
+		SortedManifests: []model.ManifestPair{
+			{
+				Key: "sha256:latest",
+				Value: model.Manifest{
+					Tags: []string{"staging-2", "latest"},
+				},
+			},
+			{
+				Key: "sha256:closed-branch",
+				Value: model.Manifest{
+					Tags: []string{"staging-closed-1", "staging-closed-latest"},
+				},
+			},
+			{
+				Key: "sha256:open-branch",
+				Value: model.Manifest{
+					Tags: []string{"staging-branch-1-1", "staging-branch-1-latest"},
+				},
+			},
+		},
+	}
+
+	imagesLoaderMock.On("List", config.Cleanup).Return(collection, nil)
+
+	oldBranchLoader := branchLoader
+	branchesLoaderMock := new(mocks.BranchLoaderInterface)
+
+	branchLoader = branchesLoaderMock
+
+	branchesLoaderMock.On("LoadBranches", config).Return([]string{"branch-1", "master"}, nil)
+
+	defer func() {
+		configLoader = oldConfigLoader
+		serviceBuilder = oldServiceBuilder
+		branchLoader = oldBranchLoader
+	}()
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdCleanup, []string{"cleanup", "-c", "never.yml", "--dry-run", "-o", "json"})
+	})
+
+	assert.Empty(t, errOutput)
+	assert.JSONEq(t, `{"command": "registry cleanup", "deletions": [{"kind": "Image", "name": "sha256:closed-branch", "details": ["staging-closed-1", "staging-closed-latest"]}]}`, output)
+	imagesLoaderMock.AssertNotCalled(t, "Untag", config.Cleanup, "staging-closed-1")
+	imagesLoaderMock.AssertNotCalled(t, "DeleteManifest", config.Cleanup, "sha256:closed-branch")
+}
@@ -0,0 +1,39 @@
+package secret
+
+import (
+	"fmt"
+
+	"kube-helper/loader"
+
+	"github.com/spf13/afero"
+	"github.com/urfave/cli"
+)
+
+// CmdEncrypt prints the encrypted content of a file, it can be used in manifests with ###decrypt:path###
+func CmdEncrypt(c *cli.Context) error {
+	if c.String("key-file") == "" {
+		return cli.NewExitError("a key file is needed, use --key-file or set "+loader.KeyFileVariable, 1)
+	}
+
+	key, err := loader.ReadSecretKey(fileSystem, c.String("key-file"))
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	plaintext, err := afero.ReadFile(fileSystem, c.Args().Get(0))
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	encrypted, err := loader.EncryptSecret(key, plaintext)
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	fmt.Fprint(writer, string(encrypted))
+
+	return nil
+}
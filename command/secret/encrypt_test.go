@@ -0,0 +1,92 @@
+package secret
+
+import (
+	"bytes"
+	"testing"
+
+	"kube-helper/command"
+	"kube-helper/loader"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func captureOutput(f func()) (string, string) {
+	oldWriter := writer
+	oldErrWriter := cli.ErrWriter
+	var buf bytes.Buffer
+	var errBuf bytes.Buffer
+	defer func() {
+		writer = oldWriter
+		cli.ErrWriter = oldErrWriter
+	}()
+	writer = &buf
+	cli.ErrWriter = &errBuf
+	f()
+	return buf.String(), errBuf.String()
+}
+
+func TestCmdEncrypt(t *testing.T) {
+	oldFileSystem := fileSystem
+	defer func() { fileSystem = oldFileSystem }()
+
+	fileSystem = afero.NewMemMapFs()
+
+	key, _ := loader.GenerateSecretKey()
+	afero.WriteFile(fileSystem, "key", []byte(key), 0600)
+	afero.WriteFile(fileSystem, "password", []byte("s3cr3t"), 0600)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdEncrypt, []string{"encrypt", "--key-file", "key", "password"})
+	})
+
+	assert.Empty(t, errOutput)
+
+	secretKey, _ := loader.ReadSecretKey(fileSystem, "key")
+	plaintext, err := loader.DecryptSecret(secretKey, []byte(output))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", string(plaintext))
+}
+
+func TestCmdEncryptWithoutKeyFile(t *testing.T) {
+	oldHandler := cli.OsExiter
+	defer func() { cli.OsExiter = oldHandler }()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdEncrypt, []string{"encrypt", "password"})
+	})
+
+	assert.Empty(t, output)
+	assert.Equal(t, "a key file is needed, use --key-file or set KUBE_HELPER_KEY_FILE\n", errOutput)
+}
+
+func TestCmdEncryptWithMissingFile(t *testing.T) {
+	oldHandler := cli.OsExiter
+	oldFileSystem := fileSystem
+	defer func() {
+		cli.OsExiter = oldHandler
+		fileSystem = oldFileSystem
+	}()
+
+	cli.OsExiter = func(exitCode int) {
+		assert.Equal(t, 1, exitCode)
+	}
+
+	fileSystem = afero.NewMemMapFs()
+
+	key, _ := loader.GenerateSecretKey()
+	afero.WriteFile(fileSystem, "key", []byte(key), 0600)
+
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdEncrypt, []string{"encrypt", "--key-file", "key", "password"})
+	})
+
+	assert.Empty(t, output)
+	assert.Equal(t, "open password: file does not exist\n", errOutput)
+}
@@ -0,0 +1,22 @@
+package secret
+
+import (
+	"fmt"
+
+	"kube-helper/loader"
+
+	"github.com/urfave/cli"
+)
+
+// CmdGenerateKey prints a new key for the encryption of secrets
+func CmdGenerateKey(c *cli.Context) error {
+	key, err := loader.GenerateSecretKey()
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	fmt.Fprintln(writer, key)
+
+	return nil
+}
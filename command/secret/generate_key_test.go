@@ -0,0 +1,24 @@
+package secret
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"kube-helper/command"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdGenerateKey(t *testing.T) {
+	output, errOutput := captureOutput(func() {
+		command.RunTestCommand(CmdGenerateKey, []string{"generate-key"})
+	})
+
+	assert.Empty(t, errOutput)
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(output))
+
+	assert.NoError(t, err)
+	assert.Len(t, key, 32)
+}
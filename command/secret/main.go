@@ -0,0 +1,11 @@
+package secret
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+var writer io.Writer = os.Stdout
+var fileSystem = afero.NewOsFs()
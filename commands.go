@@ -5,14 +5,22 @@ import (
 	"os"
 
 	"kube-helper/command/app"
+	"kube-helper/command/config"
 	"kube-helper/command/database"
 	"kube-helper/command/registry"
+	"kube-helper/command/secret"
+	"kube-helper/loader"
 
 	"github.com/urfave/cli"
 	"kube-helper/command/services"
 )
 
-var GlobalFlags = []cli.Flag{}
+var GlobalFlags = []cli.Flag{
+	cli.StringSliceFlag{
+		Name:  "env-file",
+		Usage: "load variables for placeholders from `FILE` instead of .env, can be repeated and later files override earlier ones",
+	},
+}
 
 var Commands = []cli.Command{
 	{
@@ -30,6 +38,22 @@ var Commands = []cli.Command{
 						Name:  "config, c",
 						Usage: "Load config from `FILE`",
 					},
+					cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "print what would be removed without removing anything",
+					},
+					cli.StringFlag{
+						Name:  "output, o",
+						Usage: "format of the dry run plan, `FORMAT` is text or json",
+					},
+					cli.BoolFlag{
+						Name:  "force",
+						Usage: "skip the safety checks for the list of branches and the number of deletions",
+					},
+					cli.BoolFlag{
+						Name:  "no-cache",
+						Usage: "load the branches from the provider even if a cached list exists",
+					},
 				},
 			},
 			{
@@ -72,6 +96,34 @@ var Commands = []cli.Command{
 					},
 				},
 			},
+			{
+				Name:      "render",
+				Usage:     "print the manifests like they would be applied without changing the cluster",
+				Action:    app.CmdRender,
+				ArgsUsage: "[branchName]",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "config, c",
+						Usage: "Load config from `FILE`",
+					},
+					cli.BoolFlag{
+						Name:  "production, p",
+						Usage: "render production",
+					},
+					cli.StringFlag{
+						Name:  "output-dir, d",
+						Usage: "write one file per manifest into `DIR` instead of printing them",
+					},
+					cli.BoolFlag{
+						Name:  "offline",
+						Usage: "render without a connection to the cluster, the scope of the kinds is taken from the client",
+					},
+					cli.BoolFlag{
+						Name:  "allow-unknown-kinds",
+						Usage: "render kinds which are unknown to the client as namespaced objects, only with --offline",
+					},
+				},
+			},
 			{
 				Name:      "shutdown",
 				Usage:     "",
@@ -97,6 +149,14 @@ var Commands = []cli.Command{
 						Name:  "config, c",
 						Usage: "Load config from `FILE`",
 					},
+					cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "print what would be removed without removing anything",
+					},
+					cli.StringFlag{
+						Name:  "output, o",
+						Usage: "format of the dry run plan, `FORMAT` is text or json",
+					},
 				},
 			},
 		},
@@ -115,6 +175,22 @@ var Commands = []cli.Command{
 						Name:  "config, c",
 						Usage: "Load config from `FILE`",
 					},
+					cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "print what would be removed without removing anything",
+					},
+					cli.StringFlag{
+						Name:  "output, o",
+						Usage: "format of the dry run plan, `FORMAT` is text or json",
+					},
+					cli.BoolFlag{
+						Name:  "force",
+						Usage: "skip the safety checks for the list of branches and the number of deletions",
+					},
+					cli.BoolFlag{
+						Name:  "no-cache",
+						Usage: "load the branches from the provider even if a cached list exists",
+					},
 				},
 			},
 		},
@@ -164,10 +240,93 @@ var Commands = []cli.Command{
 						Name:  "config, c",
 						Usage: "Load config from `FILE`",
 					},
+					cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "print what would be removed without removing anything",
+					},
+					cli.StringFlag{
+						Name:  "output, o",
+						Usage: "format of the dry run plan, `FORMAT` is text or json",
+					},
+					cli.BoolFlag{
+						Name:  "force",
+						Usage: "skip the safety checks for the list of branches and the number of deletions",
+					},
+					cli.BoolFlag{
+						Name:  "no-cache",
+						Usage: "load the branches from the provider even if a cached list exists",
+					},
 				},
 			},
 		},
 	},
+	{
+		Name:  "config",
+		Usage: "options around the kube-helper config",
+		Subcommands: []cli.Command{
+			{
+				Name:   "show",
+				Usage:  "print the config with the overlay of an environment",
+				Action: config.CmdShow,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "config, c",
+						Usage: "Load config from `FILE`",
+					},
+					cli.StringFlag{
+						Name:  "env",
+						Usage: "merge the overlay of `ENV` over the config, ENV is staging, production or a branch name",
+					},
+				},
+			},
+			{
+				Name:   "validate",
+				Usage:  "check the config and print every problem with its line and column",
+				Action: config.CmdValidate,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "config, c",
+						Usage: "Load config from `FILE`",
+					},
+					cli.StringFlag{
+						Name:  "env",
+						Usage: "validate the config with the overlay of `ENV`, ENV is staging, production or a branch name",
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:  "secret",
+		Usage: "encryption of secrets for ###decrypt:path### placeholders",
+		Subcommands: []cli.Command{
+			{
+				Name:   "generate-key",
+				Usage:  "print a new key for the encryption of secrets",
+				Action: secret.CmdGenerateKey,
+			},
+			{
+				Name:      "encrypt",
+				Usage:     "print the encrypted content of a file",
+				Action:    secret.CmdEncrypt,
+				ArgsUsage: "[file]",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:   "key-file",
+						Usage:  "encrypt with the key from `FILE`",
+						EnvVar: loader.KeyFileVariable,
+					},
+				},
+			},
+		},
+	},
+}
+
+// Before applies the global flags before a command is run
+func Before(c *cli.Context) error {
+	loader.SetEnvFiles(c.GlobalStringSlice("env-file"))
+
+	return nil
 }
 
 func CommandNotFound(c *cli.Context, command string) {
@@ -1,67 +1,116 @@
 package loader
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+)
 
-	"strings"
+// maxBranchPages is the upper bound of pages which are requested for one repository,
+// it protects against an endless loop if the api keeps returning a next link
+const maxBranchPages = 100
 
-	"golang.org/x/oauth2/clientcredentials"
-)
+var linkNextRegexp = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="next"`)
+
+var httpClient = http.DefaultClient
 
-type branch struct {
-	Name string `json:"name"`
+type BranchLoaderInterface interface {
+	LoadBranches(config Config) ([]string, error)
 }
 
-type branchesCollection struct {
-	Next     string   `json:"next"`
-	Branches []branch `json:"values"`
+// BranchLoader loads the branches from the provider which is selected in the branches config
+type BranchLoader struct {
 }
 
-type BranchLoaderInterface interface {
-	LoadBranches(bitbucket Bitbucket) ([]string, error)
+// LoadBranches returns the lower cased names of all branches from the configured provider, bitbucket is the default
+// the result is read from the branch cache if a ttl is configured
+func (b *BranchLoader) LoadBranches(config Config) ([]string, error) {
+	if config.Branches.Cache.TTL == "" || config.Branches.Cache.Disabled {
+		return b.loadFromProvider(config)
+	}
+
+	return new(branchCache).LoadBranches(config, b.loadFromProvider)
 }
 
-type BranchLoader struct {
+func (b *BranchLoader) loadFromProvider(config Config) ([]string, error) {
+	switch provider := config.Branches.Provider; provider {
+	case "github":
+		return new(gitHubBranchLoader).LoadBranches(config.GitHub)
+	case "gitlab":
+		return new(gitLabBranchLoader).LoadBranches(config.GitLab)
+	case "local":
+		return new(localBranchLoader).LoadBranches(config.LocalRepository)
+	case "", "bitbucket":
+		return new(bitbucketBranchLoader).LoadBranches(config.Bitbucket)
+	default:
+		return nil, fmt.Errorf("branch provider %s is not supported", provider)
+	}
 }
 
-func (b *BranchLoader) LoadBranches(bitbucket Bitbucket) ([]string, error) {
-	ctx := context.Background()
-	conf := &clientcredentials.Config{
-		ClientID:     bitbucket.ClientID,
-		ClientSecret: bitbucket.ClientSecret,
-		Scopes:       []string{"repository"},
-		TokenURL:     bitbucket.TokenUrl,
+// loadBranchPage requests one page with a json list of branches and returns the url of the next page from the link header,
+// the credentials are only sent if the page has the same scheme and host like the api url
+func loadBranchPage(pageUrl string, apiUrl string, headers map[string]string, credentials map[string]string) ([]branch, string, error) {
+	req, err := http.NewRequest("GET", pageUrl, nil)
+
+	if err != nil {
+		return nil, "", err
 	}
 
-	client := conf.Client(ctx)
-	resp, err := client.Get(fmt.Sprintf("%s/2.0/repositories/%s/%s/refs/branches?pagelen=100", bitbucket.ApiUrl, bitbucket.Username, bitbucket.RepositoryName))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if isSameOrigin(req.URL, apiUrl) {
+		for name, value := range credentials {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, err := httpClient.Do(req)
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	defer resp.Body.Close()
 
-	branches := []string{}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code %d while loading branches from %s", resp.StatusCode, pageUrl)
+	}
 
-	if resp.StatusCode == 200 { // OK
-		bodyBytes, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		var s = new(branchesCollection)
-		err = json.Unmarshal(bodyBytes, &s)
-		if err != nil {
-			return nil, err
-		}
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
 
-		for _, branch := range s.Branches {
-			branches = append(branches, strings.ToLower(branch.Name))
-		}
+	var branches []branch
+	err = json.Unmarshal(bodyBytes, &branches)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return branches, nextLink(resp.Header.Get("Link")), nil
+}
+
+func isSameOrigin(pageUrl *url.URL, apiUrl string) bool {
+	parsedApiUrl, err := url.Parse(apiUrl)
+
+	if err != nil {
+		return false
+	}
+
+	return pageUrl.Scheme == parsedApiUrl.Scheme && pageUrl.Host == parsedApiUrl.Host
+}
+
+func nextLink(linkHeader string) string {
+	matches := linkNextRegexp.FindStringSubmatch(linkHeader)
+
+	if len(matches) < 2 {
+		return ""
 	}
 
-	return branches, nil
+	return matches[1]
 }
@@ -0,0 +1,176 @@
+package loader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"strings"
+
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+type branch struct {
+	Name string `json:"name"`
+}
+
+type branchesCollection struct {
+	Next     string   `json:"next"`
+	Branches []branch `json:"values"`
+}
+
+type pullRequest struct {
+	Source struct {
+		Branch branch `json:"branch"`
+	} `json:"source"`
+}
+
+type pullRequestsCollection struct {
+	Next         string        `json:"next"`
+	PullRequests []pullRequest `json:"values"`
+}
+
+type repository struct {
+	MainBranch branch `json:"mainbranch"`
+}
+
+type bitbucketBranchLoader struct {
+}
+
+// LoadBranches returns the lower cased names of all branches of the configured repository
+// all pages of the bitbucket api are requested, so that no existing branch is missing in the result
+// with open_pull_requests_only only the source branches of open pull requests and the main branch are returned
+func (b *bitbucketBranchLoader) LoadBranches(bitbucket Bitbucket) ([]string, error) {
+	ctx := context.Background()
+	conf := &clientcredentials.Config{
+		ClientID:     bitbucket.ClientID,
+		ClientSecret: bitbucket.ClientSecret,
+		Scopes:       []string{"repository"},
+		TokenURL:     bitbucket.TokenUrl,
+	}
+
+	if bitbucket.OpenPullRequestsOnly {
+		conf.Scopes = append(conf.Scopes, "pullrequest")
+	}
+
+	client := conf.Client(ctx)
+
+	if bitbucket.OpenPullRequestsOnly {
+		return b.loadOpenPullRequestBranches(client, bitbucket)
+	}
+
+	branches := []string{}
+	url := fmt.Sprintf("%s/2.0/repositories/%s/%s/refs/branches?pagelen=100", bitbucket.ApiUrl, bitbucket.Username, bitbucket.RepositoryName)
+
+	err := b.loadPages(client, bitbucket, url, func(bodyBytes []byte) (string, error) {
+		var s = new(branchesCollection)
+		err := json.Unmarshal(bodyBytes, &s)
+		if err != nil {
+			return "", err
+		}
+
+		for _, branch := range s.Branches {
+			branches = append(branches, strings.ToLower(branch.Name))
+		}
+
+		return s.Next, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return branches, nil
+}
+
+func (b *bitbucketBranchLoader) loadOpenPullRequestBranches(client *http.Client, bitbucket Bitbucket) ([]string, error) {
+	bodyBytes, err := b.loadPage(client, fmt.Sprintf("%s/2.0/repositories/%s/%s", bitbucket.ApiUrl, bitbucket.Username, bitbucket.RepositoryName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var repo = new(repository)
+	err = json.Unmarshal(bodyBytes, &repo)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := []string{}
+	found := map[string]bool{}
+
+	addBranch := func(name string) {
+		name = strings.ToLower(name)
+		if name == "" || found[name] {
+			return
+		}
+		found[name] = true
+		branches = append(branches, name)
+	}
+
+	// the main branch has no pull request but its environment must stay alive
+	addBranch(repo.MainBranch.Name)
+
+	url := fmt.Sprintf("%s/2.0/repositories/%s/%s/pullrequests?state=OPEN&pagelen=50", bitbucket.ApiUrl, bitbucket.Username, bitbucket.RepositoryName)
+
+	err = b.loadPages(client, bitbucket, url, func(bodyBytes []byte) (string, error) {
+		var s = new(pullRequestsCollection)
+		err := json.Unmarshal(bodyBytes, &s)
+		if err != nil {
+			return "", err
+		}
+
+		for _, pullRequest := range s.PullRequests {
+			addBranch(pullRequest.Source.Branch.Name)
+		}
+
+		return s.Next, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return branches, nil
+}
+
+// loadPages requests the url and all following pages, handlePage returns the url of the next page
+func (b *bitbucketBranchLoader) loadPages(client *http.Client, bitbucket Bitbucket, url string, handlePage func(bodyBytes []byte) (string, error)) error {
+	for page := 0; url != ""; page++ {
+		if page >= maxBranchPages {
+			return fmt.Errorf("more than %d pages of branches found for repository %s/%s", maxBranchPages, bitbucket.Username, bitbucket.RepositoryName)
+		}
+
+		bodyBytes, err := b.loadPage(client, url)
+
+		if err != nil {
+			return err
+		}
+
+		url, err = handlePage(bodyBytes)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *bitbucketBranchLoader) loadPage(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d while loading branches from %s", resp.StatusCode, url)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
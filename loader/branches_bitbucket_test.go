@@ -0,0 +1,225 @@
+package loader
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitbucketGetBranches(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Basic Q2xpZW50SWQ6Q2xpZW50K1NlY3JldA==", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
+	}))
+	defer ts.Close()
+
+	branchesServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "{\"values\" : [{\"name\": \"foo\"}]}")
+	}))
+	defer branchesServer.Close()
+
+	branches, err := new(bitbucketBranchLoader).LoadBranches(Bitbucket{
+		ClientID:       "ClientId",
+		ClientSecret:   "Client Secret",
+		Username:       "Username",
+		RepositoryName: "repo",
+		TokenUrl:       ts.URL,
+		ApiUrl:         branchesServer.URL,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, branches)
+
+}
+
+func TestBitbucketGetBranchesWithPagination(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
+	}))
+	defer ts.Close()
+
+	var branchesServer *httptest.Server
+	branchesServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2.0/repositories/Username/repo/refs/branches", r.URL.Path)
+		switch r.URL.Query().Get("page") {
+		case "":
+			fmt.Fprintf(w, "{\"next\": \"%s/2.0/repositories/Username/repo/refs/branches?pagelen=100&page=2\", \"values\" : [{\"name\": \"Foo\"}]}", branchesServer.URL)
+		case "2":
+			fmt.Fprintln(w, "{\"values\" : [{\"name\": \"bar\"}]}")
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer branchesServer.Close()
+
+	branches, err := new(bitbucketBranchLoader).LoadBranches(Bitbucket{
+		ClientID:       "ClientId",
+		ClientSecret:   "Client Secret",
+		Username:       "Username",
+		RepositoryName: "repo",
+		TokenUrl:       ts.URL,
+		ApiUrl:         branchesServer.URL,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar"}, branches)
+
+}
+
+func TestBitbucketGetBranchesWithTooManyPages(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
+	}))
+	defer ts.Close()
+
+	requests := 0
+	var branchesServer *httptest.Server
+	branchesServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, "{\"next\": \"%s/next\", \"values\" : [{\"name\": \"foo\"}]}", branchesServer.URL)
+	}))
+	defer branchesServer.Close()
+
+	branches, err := new(bitbucketBranchLoader).LoadBranches(Bitbucket{
+		ClientID:       "ClientId",
+		ClientSecret:   "Client Secret",
+		Username:       "Username",
+		RepositoryName: "repo",
+		TokenUrl:       ts.URL,
+		ApiUrl:         branchesServer.URL,
+	})
+	assert.EqualError(t, err, "more than 100 pages of branches found for repository Username/repo")
+	assert.Nil(t, branches)
+	assert.Equal(t, maxBranchPages, requests)
+
+}
+
+func TestBitbucketGetBranchesWithHttpError(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Basic Q2xpZW50SWQ6Q2xpZW50K1NlY3JldA==", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
+	}))
+	defer ts.Close()
+
+	branchesServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer branchesServer.Close()
+
+	branches, err := new(bitbucketBranchLoader).LoadBranches(Bitbucket{
+		ClientID:       "ClientId",
+		ClientSecret:   "Client Secret",
+		Username:       "Username",
+		RepositoryName: "repo",
+		TokenUrl:       ts.URL,
+		ApiUrl:         branchesServer.URL,
+	})
+	assert.EqualError(t, err, "unexpected status code 500 while loading branches from "+branchesServer.URL+"/2.0/repositories/Username/repo/refs/branches?pagelen=100")
+	assert.Nil(t, branches)
+
+}
+
+func TestBitbucketGetBranchesWithHttpBodyError(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Basic Q2xpZW50SWQ6Q2xpZW50K1NlY3JldA==", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
+	}))
+	defer ts.Close()
+
+	branchesServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	}))
+	defer branchesServer.Close()
+
+	_, err := new(bitbucketBranchLoader).LoadBranches(Bitbucket{
+		ClientID:       "ClientId",
+		ClientSecret:   "Client Secret",
+		Username:       "Username",
+		RepositoryName: "repo",
+		TokenUrl:       ts.URL,
+		ApiUrl:         branchesServer.URL,
+	})
+	assert.EqualError(t, err, "unexpected end of JSON input")
+
+}
+
+func TestBitbucketGetBranchesWithOpenPullRequestsOnly(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
+	}))
+	defer ts.Close()
+
+	var branchesServer *httptest.Server
+	branchesServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2.0/repositories/Username/repo":
+			fmt.Fprintln(w, "{\"mainbranch\": {\"name\": \"master\"}}")
+		case "/2.0/repositories/Username/repo/pullrequests":
+			assert.Equal(t, "OPEN", r.URL.Query().Get("state"))
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprintln(w, "{\"values\" : [{\"source\": {\"branch\": {\"name\": \"feature-2\"}}}]}")
+				return
+			}
+			fmt.Fprintf(w, "{\"next\": \"%s/2.0/repositories/Username/repo/pullrequests?state=OPEN&page=2\", \"values\" : [{\"source\": {\"branch\": {\"name\": \"Feature-1\"}}}, {\"source\": {\"branch\": {\"name\": \"feature-1\"}}}]}", branchesServer.URL)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer branchesServer.Close()
+
+	branches, err := new(bitbucketBranchLoader).LoadBranches(Bitbucket{
+		ClientID:             "ClientId",
+		ClientSecret:         "Client Secret",
+		Username:             "Username",
+		RepositoryName:       "repo",
+		TokenUrl:             ts.URL,
+		ApiUrl:               branchesServer.URL,
+		OpenPullRequestsOnly: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master", "feature-1", "feature-2"}, branches)
+
+}
+
+func TestBitbucketGetBranchesWithOpenPullRequestsOnlyAndHttpError(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
+	}))
+	defer ts.Close()
+
+	branchesServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/2.0/repositories/Username/repo" {
+			fmt.Fprintln(w, "{\"mainbranch\": {\"name\": \"master\"}}")
+			return
+		}
+		w.WriteHeader(403)
+	}))
+	defer branchesServer.Close()
+
+	branches, err := new(bitbucketBranchLoader).LoadBranches(Bitbucket{
+		ClientID:             "ClientId",
+		ClientSecret:         "Client Secret",
+		Username:             "Username",
+		RepositoryName:       "repo",
+		TokenUrl:             ts.URL,
+		ApiUrl:               branchesServer.URL,
+		OpenPullRequestsOnly: true,
+	})
+	assert.EqualError(t, err, "unexpected status code 403 while loading branches from "+branchesServer.URL+"/2.0/repositories/Username/repo/pullrequests?state=OPEN&pagelen=50")
+	assert.Nil(t, branches)
+
+}
@@ -0,0 +1,135 @@
+package loader
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const branchCacheFilenamePattern = "branches-%s.json"
+
+var now = time.Now
+
+type branchCacheEntry struct {
+	CreatedAt time.Time `json:"created_at"`
+	Branches  []string  `json:"branches"`
+}
+
+// branchCache stores the branches of a repository on disk, so that several commands can share one branch listing
+type branchCache struct {
+}
+
+// LoadBranches returns the cached branches of the configured repository,
+// the branches are loaded with loadBranches and written to the cache if the entry is missing or older than the ttl
+func (b *branchCache) LoadBranches(config Config, loadBranches func(config Config) ([]string, error)) ([]string, error) {
+	ttl, err := time.ParseDuration(config.Branches.Cache.TTL)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid ttl for the branch cache: %s", err)
+	}
+
+	path := filepath.Join(getBranchCacheDirectory(config.Branches.Cache), fmt.Sprintf(branchCacheFilenamePattern, getBranchCacheKey(config)))
+
+	entry, err := b.read(path)
+
+	if err == nil && now().Sub(entry.CreatedAt) < ttl {
+		return entry.Branches, nil
+	}
+
+	branches, err := loadBranches(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the cache is best effort, a cache directory which is not writable must not fail the command
+	b.write(path, branchCacheEntry{CreatedAt: now(), Branches: branches})
+
+	return branches, nil
+}
+
+func (b *branchCache) read(path string) (*branchCacheEntry, error) {
+	content, err := afero.ReadFile(fileSystemWrapper, path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry := new(branchCacheEntry)
+	err = json.Unmarshal(content, entry)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (b *branchCache) write(path string, entry branchCacheEntry) error {
+	content, err := json.Marshal(entry)
+
+	if err != nil {
+		return err
+	}
+
+	err = fileSystemWrapper.MkdirAll(filepath.Dir(path), 0700)
+
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that a parallel command never reads a partially written entry
+	file, err := afero.TempFile(fileSystemWrapper, filepath.Dir(path), filepath.Base(path))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(content)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		fileSystemWrapper.Remove(file.Name())
+		return err
+	}
+
+	return fileSystemWrapper.Rename(file.Name(), path)
+}
+
+func getBranchCacheDirectory(cache BranchCache) string {
+	if cache.Directory != "" {
+		return cache.Directory
+	}
+
+	return filepath.Join(os.TempDir(), "kube-helper")
+}
+
+// getBranchCacheKey identifies the repository of the selected provider, credentials are not part of the key
+func getBranchCacheKey(config Config) string {
+	var parts []string
+
+	switch provider := config.Branches.Provider; provider {
+	case "github":
+		parts = []string{provider, config.GitHub.ApiUrl, config.GitHub.Owner, config.GitHub.RepositoryName}
+	case "gitlab":
+		parts = []string{provider, config.GitLab.ApiUrl, config.GitLab.Project}
+	case "local":
+		parts = []string{provider, config.LocalRepository.Path, config.LocalRepository.Remote}
+	default:
+		parts = []string{"bitbucket", config.Bitbucket.ApiUrl, config.Bitbucket.Username, config.Bitbucket.RepositoryName, fmt.Sprint(config.Bitbucket.OpenPullRequestsOnly)}
+	}
+
+	sum := sha1.Sum([]byte(strings.Join(parts, "\n")))
+
+	return hex.EncodeToString(sum[:])
+}
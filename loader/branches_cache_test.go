@@ -0,0 +1,175 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func getBranchCacheTestConfig(url string) Config {
+	return Config{
+		Branches: Branches{
+			Provider: "github",
+			Cache: BranchCache{
+				Directory: "/cache",
+				TTL:       "10m",
+			},
+		},
+		GitHub: GitHub{
+			Owner:          "owner",
+			RepositoryName: "repo",
+			ApiUrl:         url,
+		},
+	}
+}
+
+func TestBranchLoader_LoadBranchesFromCache(t *testing.T) {
+	oldFileSystem := fileSystemWrapper
+	oldNow := now
+	defer func() {
+		fileSystemWrapper = oldFileSystem
+		now = oldNow
+	}()
+
+	fileSystemWrapper = afero.NewMemMapFs()
+	currentTime := time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time {
+		return currentTime
+	}
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, "[{\"name\": \"master\"}, {\"name\": \"branch-%d\"}]", requests)
+	}))
+	defer ts.Close()
+
+	config := getBranchCacheTestConfig(ts.URL)
+
+	branches, err := new(BranchLoader).LoadBranches(config)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master", "branch-1"}, branches)
+
+	currentTime = currentTime.Add(9 * time.Minute)
+
+	branches, err = new(BranchLoader).LoadBranches(config)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master", "branch-1"}, branches)
+	assert.Equal(t, 1, requests)
+
+	currentTime = currentTime.Add(2 * time.Minute)
+
+	branches, err = new(BranchLoader).LoadBranches(config)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master", "branch-2"}, branches)
+	assert.Equal(t, 2, requests)
+}
+
+func TestBranchLoader_LoadBranchesWithReadOnlyCache(t *testing.T) {
+	oldFileSystem := fileSystemWrapper
+	defer func() {
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	fileSystemWrapper = afero.NewReadOnlyFs(afero.NewMemMapFs())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[{\"name\": \"master\"}]")
+	}))
+	defer ts.Close()
+
+	branches, err := new(BranchLoader).LoadBranches(getBranchCacheTestConfig(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master"}, branches)
+}
+
+func TestBranchCache_WriteReplacesEntry(t *testing.T) {
+	oldFileSystem := fileSystemWrapper
+	defer func() {
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	fileSystemWrapper = afero.NewMemMapFs()
+
+	cache := new(branchCache)
+
+	assert.NoError(t, cache.write("/cache/branches-key.json", branchCacheEntry{Branches: []string{"master"}}))
+	assert.NoError(t, cache.write("/cache/branches-key.json", branchCacheEntry{Branches: []string{"master", "feature"}}))
+
+	entry, err := cache.read("/cache/branches-key.json")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master", "feature"}, entry.Branches)
+
+	files, err := afero.ReadDir(fileSystemWrapper, "/cache")
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestBranchLoader_LoadBranchesWithDisabledCache(t *testing.T) {
+	oldFileSystem := fileSystemWrapper
+	defer func() { fileSystemWrapper = oldFileSystem }()
+
+	fileSystemWrapper = afero.NewMemMapFs()
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintln(w, "[{\"name\": \"master\"}]")
+	}))
+	defer ts.Close()
+
+	config := getBranchCacheTestConfig(ts.URL)
+	config.Branches.Cache.Disabled = true
+
+	for i := 0; i < 2; i++ {
+		_, err := new(BranchLoader).LoadBranches(config)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, requests)
+
+	exists, _ := afero.DirExists(fileSystemWrapper, "/cache")
+	assert.False(t, exists)
+}
+
+func TestBranchLoader_LoadBranchesWithInvalidCacheTTL(t *testing.T) {
+	config := getBranchCacheTestConfig("")
+	config.Branches.Cache.TTL = "ten minutes"
+
+	branches, err := new(BranchLoader).LoadBranches(config)
+	assert.Contains(t, err.Error(), "invalid ttl for the branch cache: time: invalid duration")
+	assert.Nil(t, branches)
+}
+
+func TestBranchCache_LoadBranchesDoesNotCacheErrors(t *testing.T) {
+	oldFileSystem := fileSystemWrapper
+	defer func() { fileSystemWrapper = oldFileSystem }()
+
+	fileSystemWrapper = afero.NewMemMapFs()
+
+	config := getBranchCacheTestConfig("")
+
+	branches, err := new(branchCache).LoadBranches(config, func(config Config) ([]string, error) {
+		return nil, errors.New("rate limit exceeded")
+	})
+	assert.EqualError(t, err, "rate limit exceeded")
+	assert.Nil(t, branches)
+
+	files, _ := afero.ReadDir(fileSystemWrapper, "/cache")
+	assert.Empty(t, files)
+}
+
+func TestGetBranchCacheKey(t *testing.T) {
+	config := Config{Bitbucket: Bitbucket{Username: "user", RepositoryName: "repo"}}
+	openPullRequestsConfig := Config{Bitbucket: Bitbucket{Username: "user", RepositoryName: "repo", OpenPullRequestsOnly: true}}
+	otherCredentialsConfig := Config{Bitbucket: Bitbucket{Username: "user", RepositoryName: "repo", ClientSecret: "secret"}}
+
+	assert.NotEqual(t, getBranchCacheKey(config), getBranchCacheKey(openPullRequestsConfig))
+	assert.Equal(t, getBranchCacheKey(config), getBranchCacheKey(otherCredentialsConfig))
+}
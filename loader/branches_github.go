@@ -0,0 +1,51 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+)
+
+const gitHubDefaultApiUrl = "https://api.github.com"
+
+type gitHubBranchLoader struct {
+}
+
+// LoadBranches returns the lower cased names of all branches of the configured repository
+// the pages are followed with the next relation of the link header
+func (g *gitHubBranchLoader) LoadBranches(gitHub GitHub) ([]string, error) {
+	apiUrl := gitHub.ApiUrl
+
+	if apiUrl == "" {
+		apiUrl = gitHubDefaultApiUrl
+	}
+
+	headers := map[string]string{"Accept": "application/vnd.github.v3+json"}
+	credentials := map[string]string{}
+
+	if gitHub.Token != "" {
+		credentials["Authorization"] = "token " + gitHub.Token
+	}
+
+	branches := []string{}
+	url := fmt.Sprintf("%s/repos/%s/%s/branches?per_page=100", strings.TrimSuffix(apiUrl, "/"), gitHub.Owner, gitHub.RepositoryName)
+
+	for page := 0; url != ""; page++ {
+		if page >= maxBranchPages {
+			return nil, fmt.Errorf("more than %d pages of branches found for repository %s/%s", maxBranchPages, gitHub.Owner, gitHub.RepositoryName)
+		}
+
+		pageBranches, next, err := loadBranchPage(url, apiUrl, headers, credentials)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, branch := range pageBranches {
+			branches = append(branches, strings.ToLower(branch.Name))
+		}
+
+		url = next
+	}
+
+	return branches, nil
+}
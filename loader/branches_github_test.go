@@ -0,0 +1,112 @@
+package loader
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubGetBranches(t *testing.T) {
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/v3/repos/owner/repo/branches", r.URL.Path)
+
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf("<%s/api/v3/repos/owner/repo/branches?per_page=100&page=2>; rel=\"next\", <%s/api/v3/repos/owner/repo/branches?per_page=100&page=2>; rel=\"last\"", ts.URL, ts.URL))
+			fmt.Fprintln(w, "[{\"name\": \"Master\"}, {\"name\": \"feature-1\"}]")
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf("<%s/api/v3/repos/owner/repo/branches?per_page=100&page=1>; rel=\"first\"", ts.URL))
+			fmt.Fprintln(w, "[{\"name\": \"feature-2\"}]")
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	branches, err := new(gitHubBranchLoader).LoadBranches(GitHub{
+		Token:          "secret",
+		Owner:          "owner",
+		RepositoryName: "repo",
+		ApiUrl:         ts.URL + "/api/v3/",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master", "feature-1", "feature-2"}, branches)
+}
+
+func TestGitHubGetBranchesWithHttpError(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(401)
+	}))
+	defer ts.Close()
+
+	branches, err := new(gitHubBranchLoader).LoadBranches(GitHub{
+		Owner:          "owner",
+		RepositoryName: "repo",
+		ApiUrl:         ts.URL,
+	})
+	assert.EqualError(t, err, "unexpected status code 401 while loading branches from "+ts.URL+"/repos/owner/repo/branches?per_page=100")
+	assert.Nil(t, branches)
+}
+
+func TestGitHubGetBranchesWithHttpBodyError(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	}))
+	defer ts.Close()
+
+	_, err := new(gitHubBranchLoader).LoadBranches(GitHub{
+		Owner:          "owner",
+		RepositoryName: "repo",
+		ApiUrl:         ts.URL,
+	})
+	assert.EqualError(t, err, "unexpected end of JSON input")
+}
+
+func TestGitHubGetBranchesWithTooManyPages(t *testing.T) {
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf("<%s/next>; rel=\"next\"", ts.URL))
+		fmt.Fprintln(w, "[{\"name\": \"foo\"}]")
+	}))
+	defer ts.Close()
+
+	_, err := new(gitHubBranchLoader).LoadBranches(GitHub{
+		Owner:          "owner",
+		RepositoryName: "repo",
+		ApiUrl:         ts.URL,
+	})
+	assert.EqualError(t, err, "more than 100 pages of branches found for repository owner/repo")
+}
+
+func TestGitHubGetBranchesWithNextPageOnOtherHost(t *testing.T) {
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		fmt.Fprintln(w, "[{\"name\": \"feature-2\"}]")
+	}))
+	defer other.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		w.Header().Set("Link", fmt.Sprintf("<%s/branches?page=2>; rel=\"next\"", other.URL))
+		fmt.Fprintln(w, "[{\"name\": \"master\"}]")
+	}))
+	defer ts.Close()
+
+	branches, err := new(gitHubBranchLoader).LoadBranches(GitHub{
+		Token:          "secret",
+		Owner:          "owner",
+		RepositoryName: "repo",
+		ApiUrl:         ts.URL,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master", "feature-2"}, branches)
+}
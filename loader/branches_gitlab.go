@@ -0,0 +1,51 @@
+package loader
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const gitLabDefaultApiUrl = "https://gitlab.com/api/v4"
+
+type gitLabBranchLoader struct {
+}
+
+// LoadBranches returns the lower cased names of all branches of the configured project
+// the project can be the numeric id or the full path like group/project
+func (g *gitLabBranchLoader) LoadBranches(gitLab GitLab) ([]string, error) {
+	apiUrl := gitLab.ApiUrl
+
+	if apiUrl == "" {
+		apiUrl = gitLabDefaultApiUrl
+	}
+
+	credentials := map[string]string{}
+
+	if gitLab.Token != "" {
+		credentials["PRIVATE-TOKEN"] = gitLab.Token
+	}
+
+	branches := []string{}
+	pageUrl := fmt.Sprintf("%s/projects/%s/repository/branches?per_page=100", strings.TrimSuffix(apiUrl, "/"), url.PathEscape(gitLab.Project))
+
+	for page := 0; pageUrl != ""; page++ {
+		if page >= maxBranchPages {
+			return nil, fmt.Errorf("more than %d pages of branches found for project %s", maxBranchPages, gitLab.Project)
+		}
+
+		pageBranches, next, err := loadBranchPage(pageUrl, apiUrl, nil, credentials)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, branch := range pageBranches {
+			branches = append(branches, strings.ToLower(branch.Name))
+		}
+
+		pageUrl = next
+	}
+
+	return branches, nil
+}
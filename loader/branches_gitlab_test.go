@@ -0,0 +1,72 @@
+package loader
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitLabGetBranches(t *testing.T) {
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+		assert.Equal(t, "/api/v4/projects/group%2Fproject/repository/branches", r.URL.EscapedPath())
+
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf("<%s/api/v4/projects/group%%2Fproject/repository/branches?page=2&per_page=100>; rel=\"next\"", ts.URL))
+			fmt.Fprintln(w, "[{\"name\": \"Master\"}]")
+		case "2":
+			fmt.Fprintln(w, "[{\"name\": \"feature-1\"}]")
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	branches, err := new(gitLabBranchLoader).LoadBranches(GitLab{
+		Token:   "secret",
+		Project: "group/project",
+		ApiUrl:  ts.URL + "/api/v4",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master", "feature-1"}, branches)
+}
+
+func TestGitLabGetBranchesWithProjectId(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/projects/42/repository/branches", r.URL.EscapedPath())
+		fmt.Fprintln(w, "[{\"name\": \"master\"}]")
+	}))
+	defer ts.Close()
+
+	branches, err := new(BranchLoader).LoadBranches(Config{
+		Branches: Branches{Provider: "gitlab"},
+		GitLab: GitLab{
+			Project: "42",
+			ApiUrl:  ts.URL,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master"}, branches)
+}
+
+func TestGitLabGetBranchesWithHttpError(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(403)
+	}))
+	defer ts.Close()
+
+	branches, err := new(gitLabBranchLoader).LoadBranches(GitLab{
+		Project: "42",
+		ApiUrl:  ts.URL,
+	})
+	assert.EqualError(t, err, "unexpected status code 403 while loading branches from "+ts.URL+"/projects/42/repository/branches?per_page=100")
+	assert.Nil(t, branches)
+}
@@ -0,0 +1,208 @@
+package loader
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+type localBranchLoader struct {
+}
+
+// LoadBranches returns the lower cased names of the branches in refs/heads of a local git repository
+// if a remote is configured the branches in refs/remotes/<remote> are added, loose and packed refs are read
+func (l *localBranchLoader) LoadBranches(repository LocalRepository) ([]string, error) {
+	gitDir, err := l.getGitDir(repository.Path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := []string{"refs/heads/"}
+
+	if repository.Remote != "" {
+		prefixes = append(prefixes, "refs/remotes/"+repository.Remote+"/")
+	}
+
+	refs, err := l.readPackedRefs(gitDir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	looseRefs, err := l.readLooseRefs(gitDir, prefixes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	refs = append(refs, looseRefs...)
+
+	set := map[string]struct{}{}
+
+	for _, ref := range refs {
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(ref, prefix) {
+				continue
+			}
+
+			name := strings.TrimPrefix(ref, prefix)
+
+			if name == "HEAD" {
+				continue
+			}
+
+			set[strings.ToLower(name)] = struct{}{}
+		}
+	}
+
+	// an empty list would remove everything in the cleanup commands
+	if len(set) == 0 {
+		return nil, fmt.Errorf("no branches found in %s", gitDir)
+	}
+
+	branches := []string{}
+
+	for name := range set {
+		branches = append(branches, name)
+	}
+
+	sort.Strings(branches)
+
+	return branches, nil
+}
+
+func (l *localBranchLoader) getGitDir(path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+
+	dotGit := filepath.Join(path, ".git")
+
+	info, err := fileSystemWrapper.Stat(dotGit)
+
+	if err == nil && info.IsDir() {
+		return dotGit, nil
+	}
+
+	if err == nil {
+		// worktrees and submodules contain a file which points to the real git dir
+		content, err := afero.ReadFile(fileSystemWrapper, dotGit)
+
+		if err != nil {
+			return "", err
+		}
+
+		gitDir := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(content)), "gitdir:"))
+
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(path, gitDir)
+		}
+
+		return l.getCommonDir(gitDir)
+	}
+
+	// bare repository
+	if isDir, _ := afero.IsDir(fileSystemWrapper, filepath.Join(path, "refs")); isDir {
+		return path, nil
+	}
+
+	return "", fmt.Errorf("%s is not a git repository", path)
+}
+
+// getCommonDir resolves the git dir of a worktree to the git dir of the main checkout, which contains the refs
+func (l *localBranchLoader) getCommonDir(gitDir string) (string, error) {
+	content, err := afero.ReadFile(fileSystemWrapper, filepath.Join(gitDir, "commondir"))
+
+	if os.IsNotExist(err) {
+		return gitDir, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	commonDir := strings.TrimSpace(string(content))
+
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+
+	return commonDir, nil
+}
+
+func (l *localBranchLoader) readPackedRefs(gitDir string) ([]string, error) {
+	file, err := fileSystemWrapper.Open(filepath.Join(gitDir, "packed-refs"))
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var refs []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+
+		if len(parts) == 2 {
+			refs = append(refs, parts[1])
+		}
+	}
+
+	return refs, scanner.Err()
+}
+
+func (l *localBranchLoader) readLooseRefs(gitDir string, prefixes []string) ([]string, error) {
+	var refs []string
+
+	for _, prefix := range prefixes {
+		root := filepath.Join(gitDir, filepath.FromSlash(prefix))
+
+		if exists, _ := afero.DirExists(fileSystemWrapper, root); !exists {
+			continue
+		}
+
+		err := afero.Walk(fileSystemWrapper, root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				return nil
+			}
+
+			name, err := filepath.Rel(root, path)
+
+			if err != nil {
+				return err
+			}
+
+			refs = append(refs, prefix+filepath.ToSlash(name))
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return refs, nil
+}
@@ -0,0 +1,112 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalGetBranches(t *testing.T) {
+	appFS := afero.NewMemMapFs()
+
+	afero.WriteFile(appFS, "repo/.git/HEAD", []byte("ref: refs/heads/master\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/refs/heads/master", []byte("abc\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/refs/heads/feature/JIRA-1", []byte("abc\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/refs/remotes/origin/HEAD", []byte("ref: refs/remotes/origin/master\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/refs/remotes/origin/remote-only", []byte("abc\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/refs/remotes/upstream/other", []byte("abc\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/refs/tags/v1", []byte("abc\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/packed-refs", []byte("# pack-refs with: peeled fully-peeled sorted\nabc refs/heads/packed\nabc refs/remotes/origin/master\nabc refs/tags/v0\n^def\n"), 0644)
+
+	oldFileSystem := fileSystemWrapper
+	fileSystemWrapper = appFS
+
+	defer func() {
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	branches, err := new(localBranchLoader).LoadBranches(LocalRepository{Path: "repo"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"feature/jira-1", "master", "packed"}, branches)
+
+	branches, err = new(BranchLoader).LoadBranches(Config{
+		Branches:        Branches{Provider: "local"},
+		LocalRepository: LocalRepository{Path: "repo", Remote: "origin"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"feature/jira-1", "master", "packed", "remote-only"}, branches)
+}
+
+func TestLocalGetBranchesWithGitFile(t *testing.T) {
+	appFS := afero.NewMemMapFs()
+
+	afero.WriteFile(appFS, "worktree/.git", []byte("gitdir: ../repo.git\n"), 0644)
+	afero.WriteFile(appFS, "repo.git/refs/heads/master", []byte("abc\n"), 0644)
+
+	oldFileSystem := fileSystemWrapper
+	fileSystemWrapper = appFS
+
+	defer func() {
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	branches, err := new(localBranchLoader).LoadBranches(LocalRepository{Path: "worktree"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master"}, branches)
+
+	branches, err = new(localBranchLoader).LoadBranches(LocalRepository{Path: "repo.git"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master"}, branches)
+}
+
+func TestLocalGetBranchesWithWorktree(t *testing.T) {
+	appFS := afero.NewMemMapFs()
+
+	afero.WriteFile(appFS, "worktree/.git", []byte("gitdir: ../repo/.git/worktrees/worktree\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/worktrees/worktree/HEAD", []byte("ref: refs/heads/feature-a\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/worktrees/worktree/commondir", []byte("../..\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/refs/heads/feature-a", []byte("abc\n"), 0644)
+	afero.WriteFile(appFS, "repo/.git/packed-refs", []byte("abc refs/heads/master\n"), 0644)
+
+	oldFileSystem := fileSystemWrapper
+	fileSystemWrapper = appFS
+
+	defer func() {
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	branches, err := new(localBranchLoader).LoadBranches(LocalRepository{Path: "worktree"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"feature-a", "master"}, branches)
+}
+
+func TestLocalGetBranchesWithoutBranches(t *testing.T) {
+	appFS := afero.NewMemMapFs()
+
+	afero.WriteFile(appFS, "repo/.git/HEAD", []byte("ref: refs/heads/master\n"), 0644)
+
+	oldFileSystem := fileSystemWrapper
+	fileSystemWrapper = appFS
+
+	defer func() {
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	branches, err := new(localBranchLoader).LoadBranches(LocalRepository{Path: "repo"})
+	assert.EqualError(t, err, "no branches found in repo/.git")
+	assert.Nil(t, branches)
+}
+
+func TestLocalGetBranchesWithoutRepository(t *testing.T) {
+	oldFileSystem := fileSystemWrapper
+	fileSystemWrapper = afero.NewMemMapFs()
+
+	defer func() {
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	branches, err := new(localBranchLoader).LoadBranches(LocalRepository{Path: "missing"})
+	assert.EqualError(t, err, "missing is not a git repository")
+	assert.Nil(t, branches)
+}
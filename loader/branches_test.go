@@ -9,81 +9,29 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestGetBranches(t *testing.T) {
+func TestBranchLoader_LoadBranchesFromGitHub(t *testing.T) {
 
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "Basic Q2xpZW50SWQ6Q2xpZW50K1NlY3JldA==", r.Header.Get("Authorization"))
-		w.Header().Set("Content-Type", "application/json")
-		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
+		fmt.Fprintln(w, "[{\"name\": \"Master\"}]")
 	}))
 	defer ts.Close()
 
-	branchesServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintln(w, "{\"values\" : [{\"name\": \"foo\"}]}")
-	}))
-	defer branchesServer.Close()
-
-	branches, err := new(BranchLoader).LoadBranches(Bitbucket{
-		ClientID:       "ClientId",
-		ClientSecret:   "Client Secret",
-		Username:       "Username",
-		RepositoryName: "repo",
-		TokenUrl:       ts.URL,
-		ApiUrl:         branchesServer.URL,
+	branches, err := new(BranchLoader).LoadBranches(Config{
+		Branches: Branches{Provider: "github"},
+		GitHub: GitHub{
+			Owner:          "owner",
+			RepositoryName: "repo",
+			ApiUrl:         ts.URL,
+		},
 	})
 	assert.NoError(t, err)
-	assert.Equal(t, []string{"foo"}, branches)
-
+	assert.Equal(t, []string{"master"}, branches)
 }
 
-func TestGetBranchesWithHttpError(t *testing.T) {
-
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "Basic Q2xpZW50SWQ6Q2xpZW50K1NlY3JldA==", r.Header.Get("Authorization"))
-		w.Header().Set("Content-Type", "application/json")
-		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
-	}))
-	defer ts.Close()
-
-	branchesServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(500)
-	}))
-	defer branchesServer.Close()
-
-	branches, err := new(BranchLoader).LoadBranches(Bitbucket{
-		ClientID:       "ClientId",
-		ClientSecret:   "Client Secret",
-		Username:       "Username",
-		RepositoryName: "repo",
-		TokenUrl:       ts.URL,
-		ApiUrl:         branchesServer.URL,
+func TestBranchLoader_LoadBranchesWithUnknownProvider(t *testing.T) {
+	branches, err := new(BranchLoader).LoadBranches(Config{
+		Branches: Branches{Provider: "svn"},
 	})
-	assert.NoError(t, err)
-	assert.Equal(t, []string{}, branches)
-
-}
-
-func TestGetBranchesWithHttpBodyError(t *testing.T) {
-
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "Basic Q2xpZW50SWQ6Q2xpZW50K1NlY3JldA==", r.Header.Get("Authorization"))
-		w.Header().Set("Content-Type", "application/json")
-		fmt.Fprintln(w, "{\"access_token\" : \"tolen\"}")
-	}))
-	defer ts.Close()
-
-	branchesServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-	}))
-	defer branchesServer.Close()
-
-	_, err := new(BranchLoader).LoadBranches(Bitbucket{
-		ClientID:       "ClientId",
-		ClientSecret:   "Client Secret",
-		Username:       "Username",
-		RepositoryName: "repo",
-		TokenUrl:       ts.URL,
-		ApiUrl:         branchesServer.URL,
-	})
-	assert.EqualError(t, err, "unexpected end of JSON input")
-
+	assert.EqualError(t, err, "branch provider svn is not supported")
+	assert.Nil(t, branches)
 }
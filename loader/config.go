@@ -20,10 +20,14 @@ var validate *validator.Validate
 // ConfigLoader API
 type ConfigLoader interface {
 	LoadConfigFromPath(filepath string) (Config, error)
+	ValidateConfigFromPath(filepath string) ([]ConfigProblem, error)
 }
 
 type Cleanup struct {
-	ImagePath string `yaml:"image_path"`
+	ImagePath             string `yaml:"image_path"`
+	DefaultBranch         string `yaml:"default_branch"`
+	MaxDeletions          int    `yaml:"max_deletions"`
+	MaxDeletionPercentage int    `yaml:"max_deletion_percentage"`
 }
 
 type DNSConfig struct {
@@ -36,12 +40,42 @@ type DNSConfig struct {
 }
 
 type Bitbucket struct {
-	ClientID       string `yaml:"client_id"`
-	ClientSecret   string `yaml:"client_secret"`
-	Username       string `yaml:"username"`
+	ClientID             string `yaml:"client_id"`
+	ClientSecret         string `yaml:"client_secret"`
+	Username             string `yaml:"username"`
+	RepositoryName       string `yaml:"repository_name"`
+	ApiUrl               string `yaml:"api_url"`
+	TokenUrl             string `yaml:"token_url"`
+	OpenPullRequestsOnly bool   `yaml:"open_pull_requests_only"`
+}
+
+type GitHub struct {
+	Token          string
+	Owner          string
 	RepositoryName string `yaml:"repository_name"`
 	ApiUrl         string `yaml:"api_url"`
-	TokenUrl       string `yaml:"token_url"`
+}
+
+type GitLab struct {
+	Token   string
+	Project string
+	ApiUrl  string `yaml:"api_url"`
+}
+
+type LocalRepository struct {
+	Path   string
+	Remote string
+}
+
+type BranchCache struct {
+	Directory string
+	TTL       string `yaml:"ttl"`
+	Disabled  bool
+}
+
+type Branches struct {
+	Provider string
+	Cache    BranchCache
 }
 
 type Database struct {
@@ -62,16 +96,29 @@ type Cluster struct {
 	Zone      string
 }
 
+// NetworkPolicy isolates new namespaces, only traffic from the namespace itself and the allowed sources is accepted
+type NetworkPolicy struct {
+	Isolate                bool
+	AllowedNamespaceLabels []map[string]string `yaml:"allowed_namespace_labels"`
+	AllowedCIDRs           []string            `yaml:"allowed_cidrs"`
+}
+
 type Namespace struct {
-	Prefix string
+	Prefix        string
+	NetworkPolicy NetworkPolicy `yaml:"network_policy"`
 }
 
 // Config for the kube-helper
 type Config struct {
-	KubernetesConfigFilepath string `yaml:"kubernetes_config_filepath"`
+	KubernetesConfigFilepath Paths    `yaml:"kubernetes_config_filepath"`
+	EnvFiles                 []string `yaml:"env_files"`
 	Endpoints                Endpoints
 	Cluster                  Cluster
+	Branches                 Branches
 	Bitbucket                Bitbucket
+	GitHub                   GitHub          `yaml:"github"`
+	GitLab                   GitLab          `yaml:"gitlab"`
+	LocalRepository          LocalRepository `yaml:"local_repository"`
 	Cleanup                  Cleanup
 	DNS                      DNSConfig `yaml:"dns"`
 	Database                 Database
@@ -87,14 +134,27 @@ func NewConfigLoader() ConfigLoader {
 	return &configLoader{}
 }
 
-// LoadConfigFromPath loads from a config file the Config and validates the config
+// LoadConfigFromPath loads from a config file the Config and validates the config,
+// the overlay of the environment which was selected with SetEnvEnvironment is merged over the base config
 func (c *configLoader) LoadConfigFromPath(filepath string) (Config, error) {
 	config := Config{}
 
+	configEnvFiles = readConfigEnvFiles(filepath)
+
 	err := ReplaceVariablesInFile(fileSystemWrapper, filepath, func(splitLines []string) error {
-		return yaml.Unmarshal([]byte(strings.Join(splitLines, "\n")), &config)
+		content, err := applyEnvironment([]byte(strings.Join(splitLines, "\n")), envEnvironment)
+
+		if err != nil {
+			return err
+		}
+
+		return yaml.Unmarshal(content, &config)
 	})
 
+	if err != nil {
+		return config, err
+	}
+
 	validate = validator.New()
 	err = validate.Struct(config)
 
@@ -102,7 +162,9 @@ func (c *configLoader) LoadConfigFromPath(filepath string) (Config, error) {
 		if _, ok := err.(*validator.InvalidValidationError); ok {
 			return config, err
 		}
+
+		return config, formatValidationErrors(err)
 	}
 
-	return config, err
+	return config, nil
 }
@@ -0,0 +1,79 @@
+package loader
+
+import (
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultEnvFile = ".env"
+
+// flagEnvFiles are set with the --env-file flag and take precedence over the env_files of the config
+var flagEnvFiles []string
+
+// configEnvFiles are read from the env_files of the last loaded config
+var configEnvFiles []string
+
+// envEnvironment selects the overlays of the env files and the config, e.g. .env.staging for the environment staging
+var envEnvironment string
+
+// SetEnvFiles replaces the env files of the config, which are loaded before the placeholders of a file are replaced
+func SetEnvFiles(files []string) {
+	flagEnvFiles = files
+}
+
+// SetEnvEnvironment selects the environment, for which the overlays of the env files and the config are loaded
+func SetEnvEnvironment(environment string) {
+	envEnvironment = environment
+}
+
+// getEnvFiles returns the env files in the order they need to be loaded.
+// Already set variables are not overridden while loading, so the first file wins and the precedence is
+// process environment, overlays and then the base files, a later base file or overlay overrides an earlier one.
+// The base files are required, overlays are only loaded if they exist.
+func getEnvFiles(fileSystem afero.Fs) []string {
+	baseFiles := []string{defaultEnvFile}
+
+	if len(configEnvFiles) > 0 {
+		baseFiles = configEnvFiles
+	}
+
+	if len(flagEnvFiles) > 0 {
+		baseFiles = flagEnvFiles
+	}
+
+	var files []string
+
+	if envEnvironment != "" {
+		for i := len(baseFiles) - 1; i >= 0; i-- {
+			overlay := baseFiles[i] + "." + envEnvironment
+
+			if exists, _ := afero.Exists(fileSystem, overlay); exists {
+				files = append(files, overlay)
+			}
+		}
+	}
+
+	for i := len(baseFiles) - 1; i >= 0; i-- {
+		files = append(files, baseFiles[i])
+	}
+
+	return files
+}
+
+// readConfigEnvFiles reads the env_files of a config before its placeholders are replaced,
+// errors are ignored because the config is parsed and validated afterwards
+func readConfigEnvFiles(filepath string) []string {
+	content, err := afero.ReadFile(fileSystemWrapper, filepath)
+
+	if err != nil {
+		return nil
+	}
+
+	config := struct {
+		EnvFiles []string `yaml:"env_files"`
+	}{}
+
+	yaml.Unmarshal(content, &config)
+
+	return config.EnvFiles
+}
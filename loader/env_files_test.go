@@ -0,0 +1,96 @@
+package loader
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func resetEnvFiles() {
+	flagEnvFiles = nil
+	configEnvFiles = nil
+	envEnvironment = ""
+}
+
+func TestGetEnvFilesWithDefault(t *testing.T) {
+	resetEnvFiles()
+	defer resetEnvFiles()
+
+	assert.Equal(t, []string{".env"}, getEnvFiles(afero.NewMemMapFs()))
+}
+
+func TestGetEnvFilesWithOverlays(t *testing.T) {
+	resetEnvFiles()
+	defer resetEnvFiles()
+
+	appFS := afero.NewMemMapFs()
+	afero.WriteFile(appFS, "common.env.staging", []byte(""), 0644)
+	afero.WriteFile(appFS, "app.env.staging", []byte(""), 0644)
+	afero.WriteFile(appFS, "app.env.production", []byte(""), 0644)
+
+	configEnvFiles = []string{".env"}
+	SetEnvFiles([]string{"common.env", "app.env"})
+	SetEnvEnvironment("staging")
+
+	assert.Equal(t, []string{"app.env.staging", "common.env.staging", "app.env", "common.env"}, getEnvFiles(appFS))
+}
+
+func TestGetEnvFilesFromConfig(t *testing.T) {
+	resetEnvFiles()
+	defer resetEnvFiles()
+
+	appFS := afero.NewMemMapFs()
+	afero.WriteFile(appFS, ".env.production", []byte(""), 0644)
+
+	configEnvFiles = []string{"base.env", ".env"}
+	SetEnvEnvironment("production")
+
+	assert.Equal(t, []string{".env.production", ".env", "base.env"}, getEnvFiles(appFS))
+}
+
+func TestEnvReplaceLoadsEnvFilesOfConfig(t *testing.T) {
+	resetEnvFiles()
+	defer resetEnvFiles()
+
+	os.Clearenv()
+	appFS := afero.NewMemMapFs()
+
+	var configFile = `env_files:
+  - config.env
+cluster:
+  project_id: ###FOO###
+  cluster_id: testing
+namespace:
+  prefix: test`
+
+	afero.WriteFile(appFS, "src/mainFile", []byte(configFile), 0644)
+	afero.WriteFile(appFS, "config.env.staging", []byte(""), 0644)
+
+	oldFileSystem := fileSystemWrapper
+	fileSystemWrapper = appFS
+	oldEnvReader := envLoader
+
+	defer func() {
+		envLoader = oldEnvReader
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	var loadedFiles []string
+	envLoader = func(filenames ...string) error {
+		loadedFiles = filenames
+		os.Setenv("FOO", "BAR")
+
+		return nil
+	}
+
+	SetEnvEnvironment("staging")
+
+	config, err := NewConfigLoader().LoadConfigFromPath("src/mainFile")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config.env.staging", "config.env"}, loadedFiles)
+	assert.Equal(t, []string{"config.env"}, config.EnvFiles)
+	assert.Equal(t, "BAR", config.Cluster.ProjectID)
+}
@@ -4,12 +4,10 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/afero"
-	"os"
 )
 
 var envLoader = godotenv.Load
@@ -18,58 +16,64 @@ type Callable func([]string) error
 
 type ReplaceFunc func(fileSystem afero.Fs, path string, functionCall Callable) error
 
+// ReplaceVariablesInFile replaces the placeholders in the file and calls the function for every document of the yaml stream,
+// empty documents are skipped
 func ReplaceVariablesInFile(fileSystem afero.Fs, path string, functionCall Callable) error {
+	return replaceVariablesInDocuments(fileSystem, path, func(lines []string, firstLine int) error {
+		return functionCall(lines)
+	})
+}
+
+// replaceVariablesInDocuments works like ReplaceVariablesInFile, the callback gets the line number of the document in the file as well
+func replaceVariablesInDocuments(fileSystem afero.Fs, path string, functionCall documentCallable) error {
 	file, err := fileSystem.Open(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	err = envLoader()
+	err = envLoader(getEnvFiles(fileSystem)...)
 	if err != nil {
 		return err
 	}
 
-	splitLines := []string{}
+	stream := newYamlStream(path, functionCall)
 
 	scanner := bufio.NewScanner(file)
-	re := regexp.MustCompile("###.*###")
 	variableNotFound := []string{}
+	lineNumber := 0
 	for scanner.Scan() {
-		line := scanner.Text()
-		subString := re.FindString(line)
-		if subString != "" {
-			variableName := strings.Replace(subString, "#", "", 6)
-			value, ok := os.LookupEnv(variableName)
-
-			if ok == false {
-				variableNotFound = append(variableNotFound, variableName)
-			}
-
-			line = strings.Replace(line, subString, value, 1)
-		}
-		if line == "---" {
+		lineNumber++
+		isMarker, content := stream.splitMarker(scanner.Text())
 
+		if isMarker {
 			err = checkIfVariableWasNotFound(variableNotFound)
 			if err != nil {
 				return err
 			}
-			err = functionCall(splitLines)
 
+			err = stream.flush()
 			if err != nil {
 				return err
 			}
-			splitLines = []string{}
 
-			continue
+			if content == "" {
+				continue
+			}
+		}
+
+		line, missing, err := replacePlaceholders(content, path, lineNumber)
+		if err != nil {
+			return err
 		}
-		splitLines = append(splitLines, line)
+		variableNotFound = append(variableNotFound, missing...)
+		stream.add(line, lineNumber)
 	}
 	err = checkIfVariableWasNotFound(variableNotFound)
 	if err != nil {
 		return err
 	}
-	return functionCall(splitLines)
+	return stream.flush()
 }
 
 func checkIfVariableWasNotFound(variableNotFound []string) error {
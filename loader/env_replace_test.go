@@ -89,7 +89,7 @@ func TestEnvReplaceWithErrorInCallback(t *testing.T) {
 
 		return errors.New("explode")
 	})
-	assert.EqualError(t, err, "explode")
+	assert.EqualError(t, err, "src/mainFile document 1 (line 1): explode")
 
 	assert.True(t, wasRun)
 }
@@ -109,7 +109,7 @@ func TestEnvReplaceWithEnvironmentVariableNotFound(t *testing.T) {
 	}
 
 	err := ReplaceVariablesInFile(appFS, "src/mainFile", func(splitLines []string) error {return nil})
-	assert.EqualError(t, err, "The Variables were not found in .env file: FOO, FOOBAR")
+	assert.EqualError(t, err, "The Variables were not found in .env file: FOO (src/mainFile:1), FOOBAR (src/mainFile:2)")
 
 }
 
@@ -128,6 +128,82 @@ func TestEnvReplaceWithEnvironmentVariableNotFoundForSplitFile(t *testing.T) {
 	}
 
 	err := ReplaceVariablesInFile(appFS, "src/mainFile", func(splitLines []string) error {return nil})
-	assert.EqualError(t, err, "The Variables were not found in .env file: FOOBAR")
+	assert.EqualError(t, err, "The Variables were not found in .env file: FOOBAR (src/mainFile:1)")
 
 }
+
+func TestEnvReplaceWithSeveralPlaceholdersInOneLine(t *testing.T) {
+	os.Clearenv()
+	appFS := afero.NewMemMapFs()
+	afero.WriteFile(appFS, "src/mainFile", []byte("image: ###REGISTRY###/app:###TAG:-latest###\nauth: ###USER|base64### ###MISSING:?set the password###"), 0644)
+
+	oldEnvReader := envLoader
+	defer func() { envLoader = oldEnvReader }()
+
+	envLoader = func(filenames ...string) error {
+		os.Setenv("REGISTRY", "eu.gcr.io")
+		os.Setenv("USER", "admin")
+
+		return nil
+	}
+
+	err := ReplaceVariablesInFile(appFS, "src/mainFile", func(splitLines []string) error { return nil })
+	assert.EqualError(t, err, "The Variables were not found in .env file: MISSING (src/mainFile:2: set the password)")
+
+	os.Setenv("MISSING", "secret")
+
+	splitLinesData := []string{}
+	err = ReplaceVariablesInFile(appFS, "src/mainFile", func(splitLines []string) error {
+		splitLinesData = append(splitLinesData, splitLines...)
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "image: eu.gcr.io/app:latest\nauth: YWRtaW4= secret", strings.Join(splitLinesData, "\n"))
+}
+
+func TestEnvReplaceWithYamlStreamMarkers(t *testing.T) {
+	os.Clearenv()
+	appFS := afero.NewMemMapFs()
+	afero.WriteFile(appFS, "src/mainFile", []byte("%YAML 1.2\n---\n---\n# only a comment\n\n--- # the first document\nkey: ###FOO###\n...\n---\n---   \n  \n--- test: value\n---\nkey: block\n  ---\n..."), 0644)
+
+	oldEnvReader := envLoader
+	defer func() { envLoader = oldEnvReader }()
+
+	envLoader = func(filenames ...string) error {
+		os.Setenv("FOO", "BAR")
+
+		return nil
+	}
+
+	documents := []string{}
+	err := ReplaceVariablesInFile(appFS, "src/mainFile", func(splitLines []string) error {
+		documents = append(documents, strings.Join(splitLines, "\n"))
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key: BAR", "test: value", "key: block\n  ---"}, documents)
+}
+
+func TestEnvReplaceWithErrorInCallbackContainsDocumentIndex(t *testing.T) {
+	os.Clearenv()
+	appFS := afero.NewMemMapFs()
+	afero.WriteFile(appFS, "src/mainFile", []byte("---\nkind: Service\n--- # deployment\n\n# comment\nkind: Foo\n"), 0644)
+
+	oldEnvReader := envLoader
+	defer func() { envLoader = oldEnvReader }()
+
+	envLoader = func(filenames ...string) error {
+		return nil
+	}
+
+	err := ReplaceVariablesInFile(appFS, "src/mainFile", func(splitLines []string) error {
+		if strings.Contains(strings.Join(splitLines, "\n"), "kind: Foo") {
+			return errors.New("kind Foo is not supported")
+		}
+
+		return nil
+	})
+	assert.EqualError(t, err, "src/mainFile document 2 (line 6): kind Foo is not supported")
+}
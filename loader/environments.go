@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// BranchEnvironment is the key of the config overlay for all namespaces of branches
+const BranchEnvironment = "branch"
+
+const environmentsKey = "environments"
+
+// getConfigEnvironment returns the key of the config overlay for the selected environment
+func getConfigEnvironment(environment string) string {
+	switch environment {
+	case "", StagingEnvironment, ProductionEnvironment:
+		return environment
+	default:
+		return BranchEnvironment
+	}
+}
+
+// applyEnvironment merges the overlay of the environment from the environments section over the base config,
+// maps are merged recursively and all other values are replaced
+func applyEnvironment(content []byte, environment string) ([]byte, error) {
+	config := map[interface{}]interface{}{}
+
+	err := yaml.Unmarshal(content, &config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	environments, ok := config[environmentsKey]
+
+	if !ok {
+		return content, nil
+	}
+
+	delete(config, environmentsKey)
+
+	environmentMap, ok := environments.(map[interface{}]interface{})
+
+	if !ok {
+		return nil, fmt.Errorf("%s has to be a map of %s, %s and %s", environmentsKey, StagingEnvironment, ProductionEnvironment, BranchEnvironment)
+	}
+
+	for key := range environmentMap {
+		switch key {
+		case StagingEnvironment, ProductionEnvironment, BranchEnvironment:
+		default:
+			return nil, fmt.Errorf("environment %v is not supported, use %s, %s or %s", key, StagingEnvironment, ProductionEnvironment, BranchEnvironment)
+		}
+	}
+
+	if overlay, ok := environmentMap[getConfigEnvironment(environment)]; ok && overlay != nil {
+		overlayMap, ok := overlay.(map[interface{}]interface{})
+
+		if !ok {
+			return nil, fmt.Errorf("environment %s has to be a map", getConfigEnvironment(environment))
+		}
+
+		config = mergeMaps(config, overlayMap)
+	}
+
+	return yaml.Marshal(config)
+}
+
+func mergeMaps(base map[interface{}]interface{}, overlay map[interface{}]interface{}) map[interface{}]interface{} {
+	for key, value := range overlay {
+		baseMap, baseIsMap := base[key].(map[interface{}]interface{})
+		overlayMap, overlayIsMap := value.(map[interface{}]interface{})
+
+		if baseIsMap && overlayIsMap {
+			base[key] = mergeMaps(baseMap, overlayMap)
+			continue
+		}
+
+		base[key] = value
+	}
+
+	return base
+}
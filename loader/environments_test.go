@@ -0,0 +1,119 @@
+package loader
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+var environmentsConfig = `cluster:
+  type: gcp
+  project_id: base-project
+  cluster_id: base-cluster
+dns:
+  domain_suffix: -preview
+  base_domain: example.com
+environments:
+  production:
+    cluster:
+      project_id: production-project
+    dns:
+      domain_suffix: ""
+      cname_suffix: ["www"]
+  branch:
+    database:
+      instance: branch-instance`
+
+func TestApplyEnvironment(t *testing.T) {
+	for environment, expected := range map[string]string{
+		"": `cluster:
+  cluster_id: base-cluster
+  project_id: base-project
+  type: gcp
+dns:
+  base_domain: example.com
+  domain_suffix: -preview
+`,
+		"production": `cluster:
+  cluster_id: base-cluster
+  project_id: production-project
+  type: gcp
+dns:
+  base_domain: example.com
+  cname_suffix:
+  - www
+  domain_suffix: ""
+`,
+		"feature-foo": `cluster:
+  cluster_id: base-cluster
+  project_id: base-project
+  type: gcp
+database:
+  instance: branch-instance
+dns:
+  base_domain: example.com
+  domain_suffix: -preview
+`,
+	} {
+		content, err := applyEnvironment([]byte(environmentsConfig), environment)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(content))
+	}
+}
+
+func TestApplyEnvironmentWithoutEnvironments(t *testing.T) {
+	content, err := applyEnvironment([]byte("cluster:\n  type: gcp"), ProductionEnvironment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster:\n  type: gcp", string(content))
+}
+
+func TestApplyEnvironmentWithUnknownEnvironment(t *testing.T) {
+	content, err := applyEnvironment([]byte("environments:\n  stage:\n    cluster:\n      type: gcp"), StagingEnvironment)
+
+	assert.EqualError(t, err, "environment stage is not supported, use staging, production or branch")
+	assert.Nil(t, content)
+}
+
+func TestApplyEnvironmentWithInvalidOverlay(t *testing.T) {
+	content, err := applyEnvironment([]byte("environments:\n  staging: foo"), StagingEnvironment)
+
+	assert.EqualError(t, err, "environment staging has to be a map")
+	assert.Nil(t, content)
+}
+
+func TestConfig_LoadConfigFromPathWithEnvironment(t *testing.T) {
+	resetEnvFiles()
+	defer resetEnvFiles()
+
+	os.Clearenv()
+	appFS := afero.NewMemMapFs()
+
+	afero.WriteFile(appFS, "src/mainFile", []byte(environmentsConfig+"\nnamespace:\n  prefix: test"), 0644)
+
+	oldFileSystem := fileSystemWrapper
+	fileSystemWrapper = appFS
+	oldEnvReader := envLoader
+
+	defer func() {
+		envLoader = oldEnvReader
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	envLoader = func(filenames ...string) error {
+		return nil
+	}
+
+	SetEnvEnvironment(ProductionEnvironment)
+
+	config, err := NewConfigLoader().LoadConfigFromPath("src/mainFile")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "production-project", config.Cluster.ProjectID)
+	assert.Equal(t, "base-cluster", config.Cluster.ClusterID)
+	assert.Equal(t, "", config.DNS.DomainSuffix)
+	assert.Equal(t, []string{"www"}, config.DNS.CNameSuffix)
+}
@@ -0,0 +1,135 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+var manifestExtensions = []string{".yml", ".yaml", ".json"}
+
+// Paths is a list of paths, in yaml it can be a single path or a list of paths
+type Paths []string
+
+// UnmarshalYAML accepts a single path as well as a list of paths
+func (p *Paths) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+
+	if err := unmarshal(&path); err == nil {
+		*p = Paths{}
+
+		if path != "" {
+			*p = Paths{path}
+		}
+
+		return nil
+	}
+
+	var paths []string
+
+	if err := unmarshal(&paths); err != nil {
+		return err
+	}
+
+	*p = paths
+
+	return nil
+}
+
+// ResolveManifestPaths returns the manifest files in a deterministic order.
+// A path can be a file, a directory which is searched recursively for yaml and json files or a glob pattern,
+// the files of a directory or glob are sorted by name and every file is only returned once.
+func ResolveManifestPaths(fileSystem afero.Fs, paths Paths) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no manifests are configured, set kubernetes_config_filepath")
+	}
+
+	var files []string
+	found := map[string]bool{}
+
+	add := func(path string) {
+		if !found[path] {
+			found[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, path := range paths {
+		if strings.ContainsAny(path, "*?[") {
+			matches, err := afero.Glob(fileSystem, path)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no manifests found for %s", path)
+			}
+
+			sort.Strings(matches)
+
+			for _, match := range matches {
+				add(match)
+			}
+
+			continue
+		}
+
+		if isDir, _ := afero.IsDir(fileSystem, path); !isDir {
+			// the error for a missing file is reported when the file is opened
+			add(path)
+			continue
+		}
+
+		directoryFiles, err := getManifestsOfDirectory(fileSystem, path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(directoryFiles) == 0 {
+			return nil, fmt.Errorf("no manifests found in %s", path)
+		}
+
+		for _, file := range directoryFiles {
+			add(file)
+		}
+	}
+
+	return files, nil
+}
+
+func getManifestsOfDirectory(fileSystem afero.Fs, directory string) ([]string, error) {
+	var files []string
+
+	err := afero.Walk(fileSystem, directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		for _, extension := range manifestExtensions {
+			if strings.EqualFold(filepath.Ext(path), extension) {
+				files = append(files, path)
+				break
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
@@ -0,0 +1,73 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func getManifestTestFileSystem() afero.Fs {
+	fileSystem := afero.NewMemMapFs()
+	afero.WriteFile(fileSystem, "manifests/web/deployment.yml", []byte(""), 0644)
+	afero.WriteFile(fileSystem, "manifests/web/service.yaml", []byte(""), 0644)
+	afero.WriteFile(fileSystem, "manifests/web/ingress.json", []byte(""), 0644)
+	afero.WriteFile(fileSystem, "manifests/api.yml", []byte(""), 0644)
+	afero.WriteFile(fileSystem, "manifests/README.md", []byte(""), 0644)
+	afero.WriteFile(fileSystem, "manifests/empty/README.md", []byte(""), 0644)
+	afero.WriteFile(fileSystem, "kubernetes.yml", []byte(""), 0644)
+
+	return fileSystem
+}
+
+func TestResolveManifestPathsWithFile(t *testing.T) {
+	files, err := ResolveManifestPaths(getManifestTestFileSystem(), Paths{"kubernetes.yml"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kubernetes.yml"}, files)
+}
+
+func TestResolveManifestPathsWithDirectory(t *testing.T) {
+	files, err := ResolveManifestPaths(getManifestTestFileSystem(), Paths{"manifests"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"manifests/api.yml", "manifests/web/deployment.yml", "manifests/web/ingress.json", "manifests/web/service.yaml"}, files)
+}
+
+func TestResolveManifestPathsWithGlob(t *testing.T) {
+	files, err := ResolveManifestPaths(getManifestTestFileSystem(), Paths{"manifests/web/*"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"manifests/web/deployment.yml", "manifests/web/ingress.json", "manifests/web/service.yaml"}, files)
+}
+
+func TestResolveManifestPathsWithListKeepsOrderAndRemovesDuplicates(t *testing.T) {
+	files, err := ResolveManifestPaths(getManifestTestFileSystem(), Paths{"kubernetes.yml", "manifests/web/service.yaml", "manifests"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kubernetes.yml", "manifests/web/service.yaml", "manifests/api.yml", "manifests/web/deployment.yml", "manifests/web/ingress.json"}, files)
+}
+
+func TestResolveManifestPathsWithErrors(t *testing.T) {
+	fileSystem := getManifestTestFileSystem()
+
+	_, err := ResolveManifestPaths(fileSystem, Paths{})
+	assert.EqualError(t, err, "no manifests are configured, set kubernetes_config_filepath")
+
+	_, err = ResolveManifestPaths(fileSystem, Paths{"manifests/*.txt"})
+	assert.EqualError(t, err, "no manifests found for manifests/*.txt")
+
+	_, err = ResolveManifestPaths(fileSystem, Paths{"manifests/empty"})
+	assert.EqualError(t, err, "no manifests found in manifests/empty")
+}
+
+func TestPathsUnmarshalYAML(t *testing.T) {
+	var config Config
+
+	assert.NoError(t, yaml.Unmarshal([]byte("kubernetes_config_filepath: kubernetes.yml"), &config))
+	assert.Equal(t, Paths{"kubernetes.yml"}, config.KubernetesConfigFilepath)
+
+	assert.NoError(t, yaml.Unmarshal([]byte("kubernetes_config_filepath: [manifests, extra/*.yml]"), &config))
+	assert.Equal(t, Paths{"manifests", "extra/*.yml"}, config.KubernetesConfigFilepath)
+}
@@ -0,0 +1,176 @@
+package loader
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var placeholderRegexp = regexp.MustCompile("###(.+?)###")
+
+var placeholderFilters = map[string]func(string) string{
+	"base64": func(value string) string {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	},
+	"quote": strconv.Quote,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+}
+
+// placeholder is one ###...### expression of a file, the supported syntax is
+//
+//	###VAR###            value of the variable, it is reported as missing if it is not set
+//	###VAR:-default###   default value if the variable is not set or empty
+//	###VAR:?message###   the message is reported if the variable is not set or empty
+//	###VAR|base64###     filters are applied from left to right to the value
+//	###file:/path###     content of a file
+//	###exec:command###   output of a shell command
+//	###decrypt:/path###  content of a file which was encrypted with the key from KUBE_HELPER_KEY_FILE
+//
+// Only known filter names at the end of the expression are filters, so ###VAR:-a|b### has the default value a|b.
+type placeholder struct {
+	resolver     string
+	argument     string
+	name         string
+	defaultValue string
+	hasDefault   bool
+	message      string
+	required     bool
+	filters      []string
+}
+
+func parsePlaceholder(expression string) (*placeholder, error) {
+	parts := strings.Split(expression, "|")
+	count := len(parts)
+
+	for count > 1 {
+		if _, ok := placeholderFilters[parts[count-1]]; !ok {
+			break
+		}
+
+		count--
+	}
+
+	p := new(placeholder)
+	p.name = strings.Join(parts[:count], "|")
+	p.filters = parts[count:]
+
+	if idx := strings.Index(p.name, ":"); idx != -1 {
+		if _, ok := placeholderResolvers[p.name[:idx]]; ok {
+			p.resolver = p.name[:idx]
+			p.argument = p.name[idx+1:]
+
+			return p, nil
+		}
+	}
+
+	if idx := strings.Index(p.name, ":-"); idx != -1 {
+		p.defaultValue = p.name[idx+2:]
+		p.hasDefault = true
+		p.name = p.name[:idx]
+	} else if idx := strings.Index(p.name, ":?"); idx != -1 {
+		p.message = p.name[idx+2:]
+		p.required = true
+		p.name = p.name[:idx]
+	}
+
+	if p.name == "" {
+		return nil, fmt.Errorf("placeholder ###%s### has no variable name", expression)
+	}
+
+	// a pipe is not allowed in a variable name, the part behind it was meant as a filter
+	if idx := strings.Index(p.name, "|"); idx != -1 {
+		return nil, fmt.Errorf("filter %s is not supported", strings.Split(p.name[idx+1:], "|")[0])
+	}
+
+	return p, nil
+}
+
+// resolve returns the filtered value of the placeholder and false if the variable is missing
+func (p *placeholder) resolve() (string, bool, error) {
+	if p.resolver != "" {
+		value, err := placeholderResolvers[p.resolver](p.argument)
+
+		if err != nil {
+			return "", false, err
+		}
+
+		return p.filter(value), true, nil
+	}
+
+	value, ok := os.LookupEnv(p.name)
+
+	if (p.hasDefault || p.required) && value == "" {
+		ok = false
+	}
+
+	if !ok && !p.hasDefault {
+		return "", false, nil
+	}
+
+	if !ok {
+		value = p.defaultValue
+	}
+
+	return p.filter(value), true, nil
+}
+
+func (p *placeholder) filter(value string) string {
+	for _, filter := range p.filters {
+		value = placeholderFilters[filter](value)
+	}
+
+	return value
+}
+
+// missing describes the missing variable of the placeholder for the error message
+func (p *placeholder) missing(path string, lineNumber int) string {
+	if p.message != "" {
+		return fmt.Sprintf("%s (%s:%d: %s)", p.name, path, lineNumber, p.message)
+	}
+
+	return fmt.Sprintf("%s (%s:%d)", p.name, path, lineNumber)
+}
+
+// replacePlaceholders replaces all placeholders of a line and returns the description of the missing variables
+func replacePlaceholders(line string, path string, lineNumber int) (string, []string, error) {
+	var missing []string
+	var err error
+
+	line = placeholderRegexp.ReplaceAllStringFunc(line, func(match string) string {
+		if err != nil {
+			return match
+		}
+
+		var p *placeholder
+		p, err = parsePlaceholder(strings.TrimSuffix(strings.TrimPrefix(match, "###"), "###"))
+
+		if err != nil {
+			err = fmt.Errorf("%s:%d: %s", path, lineNumber, err)
+			return match
+		}
+
+		value, ok, resolveErr := p.resolve()
+
+		if resolveErr != nil {
+			err = fmt.Errorf("%s:%d: %s", path, lineNumber, resolveErr)
+			return match
+		}
+
+		if !ok {
+			missing = append(missing, p.missing(path, lineNumber))
+		}
+
+		return value
+	})
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return line, missing, nil
+}
@@ -0,0 +1,71 @@
+package loader
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplacePlaceholders(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO", "bar")
+	os.Setenv("EMPTY", "")
+	os.Setenv("TEXT", "say \"hi\"")
+
+	for line, expected := range map[string]string{
+		"key: value":                        "key: value",
+		"key: ###FOO###":                    "key: bar",
+		"key: ###FOO###-###FOO###":          "key: bar-bar",
+		"key: ###EMPTY###":                  "key: ",
+		"key: ###EMPTY:-default###":         "key: default",
+		"key: ###UNKNOWN:-default value###": "key: default value",
+		"key: ###UNKNOWN:-###":              "key: ",
+		"key: ###FOO:-default###":           "key: bar",
+		"key: ###FOO:?not used###":          "key: bar",
+		"key: ###FOO|base64###":             "key: YmFy",
+		"key: ###FOO|upper|base64###":       "key: QkFS",
+		"key: ###UNKNOWN:-baz|upper###":     "key: BAZ",
+		"key: ###TEXT|quote###":             "key: \"say \\\"hi\\\"\"",
+		"key: ###UNKNOWN:-a|b###":           "key: a|b",
+		"key: ###UNKNOWN:-a|b|upper###":     "key: A|B",
+	} {
+		replaced, missing, err := replacePlaceholders(line, "file.yml", 1)
+		assert.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, expected, replaced)
+	}
+}
+
+func TestReplacePlaceholdersWithMissingVariables(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("EMPTY", "")
+
+	replaced, missing, err := replacePlaceholders("###UNKNOWN### ###EMPTY:?please set EMPTY### ###OTHER|base64###", "file.yml", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "  ", replaced)
+	assert.Equal(t, []string{
+		"UNKNOWN (file.yml:3)",
+		"EMPTY (file.yml:3: please set EMPTY)",
+		"OTHER (file.yml:3)",
+	}, missing)
+}
+
+func TestReplacePlaceholdersWithUnknownFilter(t *testing.T) {
+	os.Clearenv()
+
+	replaced, missing, err := replacePlaceholders("key: ###FOO|sha256###", "file.yml", 7)
+	assert.EqualError(t, err, "file.yml:7: filter sha256 is not supported")
+	assert.Empty(t, replaced)
+	assert.Nil(t, missing)
+
+	_, _, err = replacePlaceholders("key: ###FOO|sha256|base64###", "file.yml", 8)
+	assert.EqualError(t, err, "file.yml:8: filter sha256 is not supported")
+}
+
+func TestReplacePlaceholdersWithoutVariableName(t *testing.T) {
+	os.Clearenv()
+
+	_, _, err := replacePlaceholders("key: ###:-default###", "file.yml", 2)
+	assert.EqualError(t, err, "file.yml:2: placeholder ###:-default### has no variable name")
+}
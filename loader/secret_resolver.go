@@ -0,0 +1,173 @@
+package loader
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// KeyFileVariable names the variable with the path of the key, which is used for ###decrypt:...### placeholders
+const KeyFileVariable = "KUBE_HELPER_KEY_FILE"
+
+const encryptedFileHeader = "kube-helper:aes-256-gcm:"
+
+// placeholderResolvers resolve placeholders like ###file:/path### with the value of another source than the environment
+var placeholderResolvers = map[string]func(argument string) (string, error){
+	"file":    resolveFile,
+	"exec":    resolveExec,
+	"decrypt": resolveEncryptedFile,
+}
+
+// resolveFile returns the content of a file without the trailing line break
+func resolveFile(path string) (string, error) {
+	content, err := afero.ReadFile(fileSystemWrapper, path)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// resolveExec returns the output of a shell command without the trailing line break
+func resolveExec(command string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+
+	if err != nil {
+		return "", fmt.Errorf("command %s failed: %s %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// resolveEncryptedFile returns the decrypted content of a file, which was encrypted with EncryptSecret
+func resolveEncryptedFile(path string) (string, error) {
+	keyFile, ok := os.LookupEnv(KeyFileVariable)
+
+	if !ok || keyFile == "" {
+		return "", fmt.Errorf("%s is not set, it is needed to decrypt %s", KeyFileVariable, path)
+	}
+
+	key, err := ReadSecretKey(fileSystemWrapper, keyFile)
+
+	if err != nil {
+		return "", err
+	}
+
+	content, err := afero.ReadFile(fileSystemWrapper, path)
+
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := DecryptSecret(key, content)
+
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt %s: %s", path, err)
+	}
+
+	return string(plaintext), nil
+}
+
+// GenerateSecretKey returns a new random key in the format of a key file
+func GenerateSecretKey() (string, error) {
+	key := make([]byte, 32)
+
+	_, err := io.ReadFull(rand.Reader, key)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ReadSecretKey reads a key file, which contains a base64 encoded 256 bit key
+func ReadSecretKey(fileSystem afero.Fs, path string) ([]byte, error) {
+	content, err := afero.ReadFile(fileSystem, path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("key file %s does not contain a base64 encoded 256 bit key", path)
+	}
+
+	return key, nil
+}
+
+// EncryptSecret encrypts the plaintext with AES-256-GCM, the result is a text which can be stored in the repository
+func EncryptSecret(key []byte, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	_, err = io.ReadFull(rand.Reader, nonce)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	return []byte(encryptedFileHeader + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// DecryptSecret decrypts a text which was created by EncryptSecret
+func DecryptSecret(key []byte, encrypted []byte) ([]byte, error) {
+	content := strings.TrimSpace(string(encrypted))
+
+	if !strings.HasPrefix(content, encryptedFileHeader) {
+		return nil, errors.New("unknown format")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(content, encryptedFileHeader))
+
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("content is too short")
+	}
+
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
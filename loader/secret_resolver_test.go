@@ -0,0 +1,94 @@
+package loader
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplacePlaceholdersWithFile(t *testing.T) {
+	oldFileSystem := fileSystemWrapper
+	defer func() { fileSystemWrapper = oldFileSystem }()
+
+	fileSystemWrapper = afero.NewMemMapFs()
+	afero.WriteFile(fileSystemWrapper, "/secrets/password", []byte("s3cr3t\n"), 0600)
+
+	replaced, missing, err := replacePlaceholders("password: ###file:/secrets/password|base64###", "secret.yml", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, "password: czNjcjN0", replaced)
+
+	_, _, err = replacePlaceholders("password: ###file:/secrets/unknown###", "secret.yml", 2)
+	assert.EqualError(t, err, "secret.yml:2: open /secrets/unknown: file does not exist")
+}
+
+func TestReplacePlaceholdersWithExec(t *testing.T) {
+	replaced, missing, err := replacePlaceholders("token: ###exec:echo foo | (read value; echo $value-bar)###", "secret.yml", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, "token: foo-bar", replaced)
+
+	_, _, err = replacePlaceholders("token: ###exec:echo failed >&2; exit 3###", "secret.yml", 2)
+	assert.EqualError(t, err, "secret.yml:2: command echo failed >&2; exit 3 failed: exit status 3 failed")
+}
+
+func TestReplacePlaceholdersWithEncryptedFile(t *testing.T) {
+	oldFileSystem := fileSystemWrapper
+	defer func() { fileSystemWrapper = oldFileSystem }()
+
+	fileSystemWrapper = afero.NewMemMapFs()
+
+	key, err := GenerateSecretKey()
+	assert.NoError(t, err)
+
+	afero.WriteFile(fileSystemWrapper, "/keys/key", []byte(key+"\n"), 0600)
+
+	secretKey, err := ReadSecretKey(fileSystemWrapper, "/keys/key")
+	assert.NoError(t, err)
+
+	encrypted, err := EncryptSecret(secretKey, []byte("s3cr3t"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "s3cr3t")
+
+	afero.WriteFile(fileSystemWrapper, "secrets/password.enc", encrypted, 0644)
+
+	os.Clearenv()
+
+	_, _, err = replacePlaceholders("password: ###decrypt:secrets/password.enc###", "secret.yml", 1)
+	assert.EqualError(t, err, "secret.yml:1: KUBE_HELPER_KEY_FILE is not set, it is needed to decrypt secrets/password.enc")
+
+	os.Setenv(KeyFileVariable, "/keys/key")
+
+	replaced, missing, err := replacePlaceholders("password: ###decrypt:secrets/password.enc|base64###", "secret.yml", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, "password: czNjcjN0", replaced)
+
+	otherKey, _ := GenerateSecretKey()
+	afero.WriteFile(fileSystemWrapper, "/keys/key", []byte(otherKey), 0600)
+
+	_, _, err = replacePlaceholders("password: ###decrypt:secrets/password.enc###", "secret.yml", 1)
+	assert.EqualError(t, err, "secret.yml:1: could not decrypt secrets/password.enc: cipher: message authentication failed")
+}
+
+func TestReadSecretKeyWithInvalidKey(t *testing.T) {
+	oldFileSystem := fileSystemWrapper
+	defer func() { fileSystemWrapper = oldFileSystem }()
+
+	fileSystemWrapper = afero.NewMemMapFs()
+	afero.WriteFile(fileSystemWrapper, "/keys/key", []byte("c2hvcnQ="), 0600)
+
+	key, err := ReadSecretKey(fileSystemWrapper, "/keys/key")
+	assert.EqualError(t, err, "key file /keys/key does not contain a base64 encoded 256 bit key")
+	assert.Nil(t, key)
+}
+
+func TestDecryptSecretWithUnknownFormat(t *testing.T) {
+	key, _ := GenerateSecretKey()
+
+	plaintext, err := DecryptSecret([]byte(key)[:32], []byte("plain text"))
+	assert.EqualError(t, err, "unknown format")
+	assert.Nil(t, plaintext)
+}
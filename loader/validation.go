@@ -0,0 +1,381 @@
+package loader
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/go-playground/validator.v9"
+	"gopkg.in/yaml.v2"
+)
+
+var yamlKeyRegexp = regexp.MustCompile(`^( *)([A-Za-z0-9_.-]+):(?:\s|$)`)
+var yamlErrorRegexp = regexp.MustCompile(`line (\d+): (.+)`)
+var yamlUnknownKeyRegexp = regexp.MustCompile(`^field (\S+) not found in type`)
+
+// strictConfig is used to reject unknown keys in the config and in the overlays of the environments
+type strictConfig struct {
+	Config       `yaml:",inline"`
+	Environments map[string]Config `yaml:"environments"`
+}
+
+// ConfigProblem is one finding of the config validation, line and column are 0 if the position is unknown
+type ConfigProblem struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p ConfigProblem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+type yamlPosition struct {
+	line   int
+	column int
+}
+
+// ValidateConfigFromPath checks the config with the overlay of the selected environment against the used features.
+// Unknown keys and invalid values are reported first, the error is only set if the config could not be read at all.
+func (c *configLoader) ValidateConfigFromPath(filepath string) ([]ConfigProblem, error) {
+	configEnvFiles = readConfigEnvFiles(filepath)
+
+	var lines []string
+
+	// the documents keep the line numbers of the file, markers, directives and comments in front of them are replaced by empty lines
+	err := replaceVariablesInDocuments(fileSystemWrapper, filepath, func(splitLines []string, firstLine int) error {
+		if len(lines) > 0 {
+			for len(lines) < firstLine-2 {
+				lines = append(lines, "")
+			}
+
+			lines = append(lines, "---")
+		}
+
+		for len(lines) < firstLine-1 {
+			lines = append(lines, "")
+		}
+
+		lines = append(lines, splitLines...)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	content := []byte(strings.Join(lines, "\n"))
+	positions := getYamlPositions(content)
+
+	err = yaml.UnmarshalStrict(content, &strictConfig{})
+
+	if err != nil {
+		return getYamlErrors(err, content, positions), nil
+	}
+
+	effectiveContent, err := applyEnvironment(content, envEnvironment)
+
+	if err != nil {
+		return []ConfigProblem{{Path: environmentsKey, Message: err.Error()}}, nil
+	}
+
+	config := Config{}
+	err = yaml.Unmarshal(effectiveContent, &config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []ConfigProblem
+
+	for _, violation := range checkConfig(config) {
+		problem := violation.problem
+		problem.Line, problem.Column = findPosition(positions, getConfigEnvironment(envEnvironment), problem.Path, violation.trigger)
+		problems = append(problems, problem)
+	}
+
+	// problems without a position are listed last
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line == 0 || problems[j].Line == 0 {
+			return problems[j].Line == 0 && problems[i].Line != 0
+		}
+
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+
+		return problems[i].Column < problems[j].Column
+	})
+
+	return problems, nil
+}
+
+// configViolation is a problem of a rule, the trigger is the key which made the rule necessary
+// and is used as position if the key of the problem is missing
+type configViolation struct {
+	problem ConfigProblem
+	trigger string
+}
+
+// checkConfig contains the rules which depend on the used features
+func checkConfig(config Config) []configViolation {
+	var violations []configViolation
+
+	add := func(path string, trigger string, message string) {
+		violations = append(violations, configViolation{problem: ConfigProblem{Path: path, Message: message}, trigger: trigger})
+	}
+
+	required := func(path string, value string, trigger string, reason string) {
+		if value == "" {
+			add(path, trigger, strings.TrimSpace(path+" is required "+reason))
+		}
+	}
+
+	required("cluster.project_id", config.Cluster.ProjectID, "cluster", "")
+	required("cluster.cluster_id", config.Cluster.ClusterID, "cluster", "")
+
+	switch config.Cluster.Type {
+	case "", "gcp":
+		required("cluster.zone", config.Cluster.Zone, "cluster.type", "for the cluster type gcp")
+
+		if !reflect.DeepEqual(config.DNS, DNSConfig{}) {
+			required("dns.managed_zone", config.DNS.ManagedZone, "dns", "when the cluster type is gcp")
+			required("dns.project_id", config.DNS.ProjectID, "dns", "when the cluster type is gcp")
+		}
+	case "local":
+	default:
+		add("cluster.type", "cluster.type", fmt.Sprintf("cluster.type %s is not supported, use gcp or local", config.Cluster.Type))
+	}
+
+	if config.Database.Instance != "" {
+		required("database.base_name", config.Database.BaseName, "database.instance", "when database.instance is set")
+		required("database.bucket", config.Database.Bucket, "database.instance", "when database.instance is set")
+		required("database.prefix_branch_database", config.Database.PrefixBranchDatabase, "database.instance", "when database.instance is set")
+	}
+
+	switch provider := config.Branches.Provider; provider {
+	case "github":
+		required("github.owner", config.GitHub.Owner, "branches.provider", "for the branch provider github")
+		required("github.repository_name", config.GitHub.RepositoryName, "branches.provider", "for the branch provider github")
+	case "gitlab":
+		required("gitlab.project", config.GitLab.Project, "branches.provider", "for the branch provider gitlab")
+	case "local":
+		required("local_repository.path", config.LocalRepository.Path, "branches.provider", "for the branch provider local")
+	case "", "bitbucket":
+		if provider == "" && config.Bitbucket == (Bitbucket{}) {
+			break
+		}
+
+		required("bitbucket.client_id", config.Bitbucket.ClientID, "bitbucket", "for the branch provider bitbucket")
+		required("bitbucket.client_secret", config.Bitbucket.ClientSecret, "bitbucket", "for the branch provider bitbucket")
+		required("bitbucket.username", config.Bitbucket.Username, "bitbucket", "for the branch provider bitbucket")
+		required("bitbucket.repository_name", config.Bitbucket.RepositoryName, "bitbucket", "for the branch provider bitbucket")
+	default:
+		add("branches.provider", "branches.provider", fmt.Sprintf("branch provider %s is not supported, use bitbucket, github, gitlab or local", provider))
+	}
+
+	if config.Branches.Cache.TTL != "" {
+		if _, err := time.ParseDuration(config.Branches.Cache.TTL); err != nil {
+			add("branches.cache.ttl", "branches.cache.ttl", fmt.Sprintf("branches.cache.ttl %s is not a valid duration, e.g. 10m", config.Branches.Cache.TTL))
+		}
+	}
+
+	for _, cidr := range config.Namespace.NetworkPolicy.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			add("namespace.network_policy.allowed_cidrs", "namespace.network_policy.allowed_cidrs", fmt.Sprintf("namespace.network_policy.allowed_cidrs %s is not a valid cidr, e.g. 10.0.0.0/8", cidr))
+		}
+	}
+
+	if config.Cleanup.MaxDeletions < 0 {
+		add("cleanup.max_deletions", "cleanup.max_deletions", "cleanup.max_deletions must not be negative")
+	}
+
+	if config.Cleanup.MaxDeletionPercentage < 0 || config.Cleanup.MaxDeletionPercentage > 100 {
+		add("cleanup.max_deletion_percentage", "cleanup.max_deletion_percentage", "cleanup.max_deletion_percentage must be between 0 and 100")
+	}
+
+	return violations
+}
+
+// getYamlPositions returns the position of every key of a block style yaml document by its dotted path
+func getYamlPositions(content []byte) map[string]yamlPosition {
+	type parent struct {
+		indent int
+		key    string
+	}
+
+	positions := map[string]yamlPosition{}
+	var parents []parent
+
+	for idx, line := range strings.Split(string(content), "\n") {
+		if line == "---" {
+			parents = nil
+			continue
+		}
+
+		matches := yamlKeyRegexp.FindStringSubmatch(line)
+
+		if matches == nil {
+			continue
+		}
+
+		indent := len(matches[1])
+
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+
+		var keys []string
+		for _, p := range parents {
+			keys = append(keys, p.key)
+		}
+
+		path := strings.Join(append(keys, matches[2]), ".")
+
+		if _, ok := positions[path]; !ok {
+			positions[path] = yamlPosition{line: idx + 1, column: indent + 1}
+		}
+
+		parents = append(parents, parent{indent: indent, key: matches[2]})
+	}
+
+	return positions
+}
+
+// getYamlName returns the key which yaml.v2 uses for a field
+func getYamlName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+
+	if name != "" {
+		return name
+	}
+
+	return strings.ToLower(field.Name)
+}
+
+// getYamlPath converts the namespace of a validator error like Config.Cluster.ProjectID into cluster.project_id
+func getYamlPath(namespace string) string {
+	configType := reflect.TypeOf(Config{})
+	var keys []string
+
+	for _, name := range strings.Split(namespace, ".")[1:] {
+		field, ok := configType.FieldByName(name)
+
+		if !ok {
+			return strings.ToLower(namespace)
+		}
+
+		keys = append(keys, getYamlName(field))
+		configType = field.Type
+	}
+
+	return strings.Join(keys, ".")
+}
+
+// findPosition returns the position of the first path which is found in the overlay of the environment,
+// the base config or as its nearest parent
+func findPosition(positions map[string]yamlPosition, environment string, paths ...string) (int, int) {
+	for _, path := range paths {
+		if line, column := findPathPosition(positions, environment, path); line != 0 {
+			return line, column
+		}
+	}
+
+	return 0, 0
+}
+
+func findPathPosition(positions map[string]yamlPosition, environment string, path string) (int, int) {
+	for path != "" {
+		if environment != "" {
+			if position, ok := positions[environmentsKey+"."+environment+"."+path]; ok {
+				return position.line, position.column
+			}
+		}
+
+		if position, ok := positions[path]; ok {
+			return position.line, position.column
+		}
+
+		idx := strings.LastIndex(path, ".")
+
+		if idx == -1 {
+			break
+		}
+
+		path = path[:idx]
+	}
+
+	return 0, 0
+}
+
+// getYamlErrors converts the errors of the yaml decoder, an unknown key is reported with its path
+func getYamlErrors(err error, content []byte, positions map[string]yamlPosition) []ConfigProblem {
+	messages := []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+
+	if typeError, ok := err.(*yaml.TypeError); ok {
+		messages = typeError.Errors
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	var problems []ConfigProblem
+
+	for _, message := range messages {
+		matches := yamlErrorRegexp.FindStringSubmatch(message)
+
+		if matches == nil {
+			problems = append(problems, ConfigProblem{Message: message})
+			continue
+		}
+
+		problem := ConfigProblem{Message: matches[2]}
+		problem.Line, _ = strconv.Atoi(matches[1])
+
+		if problem.Line > 0 && problem.Line <= len(lines) {
+			line := lines[problem.Line-1]
+			problem.Column = len(line) - len(strings.TrimLeft(line, " ")) + 1
+		}
+
+		for path, position := range positions {
+			if position.line == problem.Line {
+				problem.Path = path
+			}
+		}
+
+		if unknownKey := yamlUnknownKeyRegexp.FindStringSubmatch(problem.Message); unknownKey != nil && problem.Path != "" {
+			problem.Message = fmt.Sprintf("%s is not a known key", problem.Path)
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems
+}
+
+// formatValidationErrors converts the errors of the validator into a readable error with the keys of the yaml file
+func formatValidationErrors(err error) error {
+	validationErrors, ok := err.(validator.ValidationErrors)
+
+	if !ok {
+		return err
+	}
+
+	var messages []string
+
+	for _, fieldError := range validationErrors {
+		messages = append(messages, fmt.Sprintf("%s is %s", getYamlPath(fieldError.Namespace()), fieldError.Tag()))
+	}
+
+	return fmt.Errorf("invalid config: %s", strings.Join(messages, ", "))
+}
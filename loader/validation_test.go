@@ -0,0 +1,200 @@
+package loader
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func validateTestConfig(t *testing.T, content string, environment string) ([]ConfigProblem, error) {
+	resetEnvFiles()
+	os.Clearenv()
+
+	appFS := afero.NewMemMapFs()
+	afero.WriteFile(appFS, "config.yml", []byte(content), 0644)
+
+	oldFileSystem := fileSystemWrapper
+	fileSystemWrapper = appFS
+	oldEnvReader := envLoader
+
+	defer func() {
+		envLoader = oldEnvReader
+		fileSystemWrapper = oldFileSystem
+		resetEnvFiles()
+	}()
+
+	envLoader = func(filenames ...string) error {
+		return nil
+	}
+
+	SetEnvEnvironment(environment)
+
+	return NewConfigLoader().ValidateConfigFromPath("config.yml")
+}
+
+func TestConfig_ValidateConfigFromPath(t *testing.T) {
+	problems, err := validateTestConfig(t, `cluster:
+  type: gcp
+  project_id: project
+  cluster_id: cluster
+  zone: europe-west1-d
+database:
+  instance: instance
+  base_name: app
+  bucket: backups
+  prefix_branch_database: branch_
+`, "")
+
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestConfig_ValidateConfigFromPathWithConditionalRules(t *testing.T) {
+	problems, err := validateTestConfig(t, `cluster:
+  type: gcp
+  project_id: project
+  zone: europe-west1-d
+dns:
+  base_domain: example.com
+branches:
+  provider: github
+  cache:
+    ttl: ten minutes
+database:
+  instance: instance
+  base_name: app
+cleanup:
+  max_deletion_percentage: 120
+namespace:
+  network_policy:
+    isolate: true
+    allowed_cidrs: [130.211.0.0/22, 35.191.0.0]
+`, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ConfigProblem{
+		{Path: "cluster.cluster_id", Line: 1, Column: 1, Message: "cluster.cluster_id is required"},
+		{Path: "dns.managed_zone", Line: 5, Column: 1, Message: "dns.managed_zone is required when the cluster type is gcp"},
+		{Path: "dns.project_id", Line: 5, Column: 1, Message: "dns.project_id is required when the cluster type is gcp"},
+		{Path: "github.owner", Line: 8, Column: 3, Message: "github.owner is required for the branch provider github"},
+		{Path: "github.repository_name", Line: 8, Column: 3, Message: "github.repository_name is required for the branch provider github"},
+		{Path: "branches.cache.ttl", Line: 10, Column: 5, Message: "branches.cache.ttl ten minutes is not a valid duration, e.g. 10m"},
+		{Path: "database.bucket", Line: 11, Column: 1, Message: "database.bucket is required when database.instance is set"},
+		{Path: "database.prefix_branch_database", Line: 11, Column: 1, Message: "database.prefix_branch_database is required when database.instance is set"},
+		{Path: "cleanup.max_deletion_percentage", Line: 15, Column: 3, Message: "cleanup.max_deletion_percentage must be between 0 and 100"},
+		{Path: "namespace.network_policy.allowed_cidrs", Line: 19, Column: 5, Message: "namespace.network_policy.allowed_cidrs 35.191.0.0 is not a valid cidr, e.g. 10.0.0.0/8"},
+	}, problems)
+}
+
+func TestConfig_ValidateConfigFromPathWithUnknownKeys(t *testing.T) {
+	problems, err := validateTestConfig(t, `cluster:
+  project_id: project
+  cluster_id: cluster
+  zone: europe-west1-d
+  regoin: europe-west1
+environments:
+  production:
+    databse:
+      instance: production
+`, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ConfigProblem{
+		{Path: "cluster.regoin", Line: 5, Column: 3, Message: "cluster.regoin is not a known key"},
+		{Path: "environments.production.databse", Line: 8, Column: 5, Message: "environments.production.databse is not a known key"},
+	}, problems)
+}
+
+func TestConfig_ValidateConfigFromPathWithDocumentMarkers(t *testing.T) {
+	problems, err := validateTestConfig(t, `%YAML 1.1
+---
+# kube-helper config
+cluster:
+  project_id: project
+  cluster_id: cluster
+  zone: europe-west1-d
+  regoin: europe-west1
+...
+`, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ConfigProblem{
+		{Path: "cluster.regoin", Line: 8, Column: 3, Message: "cluster.regoin is not a known key"},
+	}, problems)
+}
+
+func TestConfig_ValidateConfigFromPathWithInvalidType(t *testing.T) {
+	problems, err := validateTestConfig(t, `cluster:
+  project_id: project
+  cluster_id: cluster
+  zone: europe-west1-d
+endpoints:
+  enabled: sometimes
+`, "")
+
+	assert.NoError(t, err)
+	assert.Len(t, problems, 1)
+	assert.Equal(t, "endpoints.enabled", problems[0].Path)
+	assert.Equal(t, 6, problems[0].Line)
+	assert.Equal(t, 3, problems[0].Column)
+	assert.Contains(t, problems[0].Message, "cannot unmarshal !!str `sometimes` into bool")
+	assert.Equal(t, "6:3: "+problems[0].Message, problems[0].String())
+}
+
+func TestConfig_ValidateConfigFromPathWithEnvironment(t *testing.T) {
+	content := `cluster:
+  project_id: project
+  cluster_id: cluster
+  zone: europe-west1-d
+environments:
+  production:
+    cluster:
+      type: aws
+`
+
+	problems, err := validateTestConfig(t, content, "")
+
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+
+	problems, err = validateTestConfig(t, content, ProductionEnvironment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ConfigProblem{
+		{Path: "cluster.type", Line: 8, Column: 7, Message: "cluster.type aws is not supported, use gcp or local"},
+	}, problems)
+}
+
+func TestConfig_ValidateConfigFromPathWithMissingFile(t *testing.T) {
+	problems, err := NewConfigLoader().ValidateConfigFromPath("/not/existing/config.yml")
+
+	assert.EqualError(t, err, "open /not/existing/config.yml: no such file or directory")
+	assert.Nil(t, problems)
+}
+
+func TestConfig_LoadConfigFromPathWithValidationErrors(t *testing.T) {
+	resetEnvFiles()
+	os.Clearenv()
+
+	appFS := afero.NewMemMapFs()
+	afero.WriteFile(appFS, "config.yml", []byte("cluster:\n  type: gcp"), 0644)
+
+	oldFileSystem := fileSystemWrapper
+	fileSystemWrapper = appFS
+	oldEnvReader := envLoader
+
+	defer func() {
+		envLoader = oldEnvReader
+		fileSystemWrapper = oldFileSystem
+	}()
+
+	envLoader = func(filenames ...string) error {
+		return nil
+	}
+
+	_, err := NewConfigLoader().LoadConfigFromPath("config.yml")
+
+	assert.EqualError(t, err, "invalid config: cluster.project_id is required, cluster.cluster_id is required")
+}
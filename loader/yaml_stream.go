@@ -0,0 +1,99 @@
+package loader
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var documentStartRegexp = regexp.MustCompile(`^---(\s|$)`)
+var documentEndRegexp = regexp.MustCompile(`^\.\.\.(\s|$)`)
+
+// documentCallable is called with the lines of a document and the line number of its first line in the file
+type documentCallable func(lines []string, firstLine int) error
+
+// yamlStream collects the lines of a yaml stream and passes every document which is not empty to the callback
+type yamlStream struct {
+	path      string
+	callback  documentCallable
+	lines     []string
+	firstLine int
+	startLine int
+	index     int
+}
+
+func newYamlStream(path string, callback documentCallable) *yamlStream {
+	return &yamlStream{
+		path:     path,
+		callback: callback,
+	}
+}
+
+// splitMarker checks if the line starts or ends a document, for a start marker the content after it is returned
+func (s *yamlStream) splitMarker(line string) (isMarker bool, content string) {
+	if documentEndRegexp.MatchString(line) {
+		return true, ""
+	}
+
+	if !documentStartRegexp.MatchString(line) {
+		return false, line
+	}
+
+	content = strings.TrimSpace(line[3:])
+
+	if strings.HasPrefix(content, "#") {
+		content = ""
+	}
+
+	return true, content
+}
+
+func (s *yamlStream) add(line string, lineNumber int) {
+	if isEmptyDocument(s.lines) {
+		// directives are only allowed in front of a document and are not part of it
+		if strings.HasPrefix(line, "%") {
+			return
+		}
+
+		s.startLine = lineNumber
+	}
+
+	if len(s.lines) == 0 {
+		s.firstLine = lineNumber
+	}
+
+	s.lines = append(s.lines, line)
+}
+
+// isEmptyDocument checks if the lines only contain whitespace and comments
+func isEmptyDocument(lines []string) bool {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flush passes the current document to the callback, errors of the callback contain the file and the document index
+func (s *yamlStream) flush() error {
+	lines := s.lines
+	s.lines = []string{}
+
+	if isEmptyDocument(lines) {
+		return nil
+	}
+
+	s.index++
+
+	err := s.callback(lines, s.firstLine)
+
+	if err != nil {
+		return fmt.Errorf("%s document %d (line %d): %s", s.path, s.index, s.startLine, err)
+	}
+
+	return nil
+}
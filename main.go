@@ -15,6 +15,7 @@ func main() {
 	app.Usage = ""
 
 	app.Flags = GlobalFlags
+	app.Before = Before
 	app.Commands = Commands
 	app.CommandNotFound = CommandNotFound
 
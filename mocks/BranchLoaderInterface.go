@@ -8,13 +8,13 @@ type BranchLoaderInterface struct {
 	mock.Mock
 }
 
-// LoadBranches provides a mock function with given fields: bitbucket
-func (_m *BranchLoaderInterface) LoadBranches(bitbucket loader.Bitbucket) ([]string, error) {
-	ret := _m.Called(bitbucket)
+// LoadBranches provides a mock function with given fields: config
+func (_m *BranchLoaderInterface) LoadBranches(config loader.Config) ([]string, error) {
+	ret := _m.Called(config)
 
 	var r0 []string
-	if rf, ok := ret.Get(0).(func(loader.Bitbucket) []string); ok {
-		r0 = rf(bitbucket)
+	if rf, ok := ret.Get(0).(func(loader.Config) []string); ok {
+		r0 = rf(config)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]string)
@@ -22,8 +22,8 @@ func (_m *BranchLoaderInterface) LoadBranches(bitbucket loader.Bitbucket) ([]str
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(loader.Bitbucket) error); ok {
-		r1 = rf(bitbucket)
+	if rf, ok := ret.Get(1).(func(loader.Config) error); ok {
+		r1 = rf(config)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -28,3 +28,26 @@ func (_m *ConfigLoader) LoadConfigFromPath(filepath string) (loader.Config, erro
 
 	return r0, r1
 }
+
+// ValidateConfigFromPath provides a mock function with given fields: filepath
+func (_m *ConfigLoader) ValidateConfigFromPath(filepath string) ([]loader.ConfigProblem, error) {
+	ret := _m.Called(filepath)
+
+	var r0 []loader.ConfigProblem
+	if rf, ok := ret.Get(0).(func(string) []loader.ConfigProblem); ok {
+		r0 = rf(filepath)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]loader.ConfigProblem)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(filepath)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import mock "github.com/stretchr/testify/mock"
+import runtime "k8s.io/apimachinery/pkg/runtime"
 
 // KindInterface is an autogenerated mock type for the KindInterface type
 type KindInterface struct {
@@ -34,3 +35,26 @@ func (_m *KindInterface) CleanupKind(kubernetesNamespace string) error {
 
 	return r0
 }
+
+// RenderKind provides a mock function with given fields: kubernetesNamespace, fileLines, namespaceWithoutPrefix
+func (_m *KindInterface) RenderKind(kubernetesNamespace string, fileLines []string, namespaceWithoutPrefix string) ([]runtime.Object, error) {
+	ret := _m.Called(kubernetesNamespace, fileLines, namespaceWithoutPrefix)
+
+	var r0 []runtime.Object
+	if rf, ok := ret.Get(0).(func(string, []string, string) []runtime.Object); ok {
+		r0 = rf(kubernetesNamespace, fileLines, namespaceWithoutPrefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]runtime.Object)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []string, string) error); ok {
+		r1 = rf(kubernetesNamespace, fileLines, namespaceWithoutPrefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -4,6 +4,7 @@ import bucket "kube-helper/service/bucket"
 
 import compute "google.golang.org/api/compute/v1"
 import dns "google.golang.org/api/dns/v1"
+import dynamic "k8s.io/client-go/dynamic"
 import image "kube-helper/service/image"
 import kubernetes "k8s.io/client-go/kubernetes"
 import loader "kube-helper/loader"
@@ -39,6 +40,29 @@ func (_m *ServiceBuilderInterface) GetClientSet(config loader.Config) (kubernete
 	return r0, r1
 }
 
+// GetDynamicClientPool provides a mock function with given fields: config
+func (_m *ServiceBuilderInterface) GetDynamicClientPool(config loader.Config) (dynamic.ClientPool, error) {
+	ret := _m.Called(config)
+
+	var r0 dynamic.ClientPool
+	if rf, ok := ret.Get(0).(func(loader.Config) dynamic.ClientPool); ok {
+		r0 = rf(config)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(dynamic.ClientPool)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(loader.Config) error); ok {
+		r1 = rf(config)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetComputeService provides a mock function with given fields:
 func (_m *ServiceBuilderInterface) GetComputeService() (*compute.Service, error) {
 	ret := _m.Called()
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Plan contains the resources which a destructive command would delete, it is printed instead on a dry run
+type Plan struct {
+	Command    string      `json:"command"`
+	Deletions  []PlanEntry `json:"deletions"`
+	Violations []string    `json:"violations,omitempty"`
+}
+
+// PlanEntry is a single resource of a plan, details contain e.g. the tags of an image
+type PlanEntry struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Details []string `json:"details,omitempty"`
+}
+
+// NewPlan creates an empty plan for the given command
+func NewPlan(command string) *Plan {
+	return &Plan{Command: command, Deletions: []PlanEntry{}}
+}
+
+// Add appends a resource which would be deleted to the plan
+func (p *Plan) Add(kind string, name string, details ...string) {
+	p.Deletions = append(p.Deletions, PlanEntry{Kind: kind, Name: name, Details: details})
+}
+
+// AddViolation records a safety check which would refuse the cleanup without a dry run
+func (p *Plan) AddViolation(reason string) {
+	p.Violations = append(p.Violations, reason)
+}
+
+// Print writes the plan as text or json to the writer
+func (p *Plan) Print(writer io.Writer, format string) error {
+	switch format {
+	case "", "text":
+		fmt.Fprintf(writer, "Dry run of \"%s\", nothing was removed.\n", p.Command)
+		for _, violation := range p.Violations {
+			fmt.Fprintf(writer, "Safety check failed, the cleanup would be refused: %s\n", violation)
+		}
+		for _, entry := range p.Deletions {
+			if len(entry.Details) > 0 {
+				fmt.Fprintf(writer, "%s \"%s\" would be removed (%s).\n", entry.Kind, entry.Name, strings.Join(entry.Details, ", "))
+				continue
+			}
+			fmt.Fprintf(writer, "%s \"%s\" would be removed.\n", entry.Kind, entry.Name)
+		}
+		return nil
+	case "json":
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(p)
+	default:
+		return fmt.Errorf("output format %s is not supported", format)
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan_PrintText(t *testing.T) {
+	plan := NewPlan("registry cleanup")
+	plan.Add("Image", "sha256:foo", "staging-foo-1", "staging-foo-latest")
+	plan.Add("Database", "base_foo")
+
+	var buf bytes.Buffer
+	assert.NoError(t, plan.Print(&buf, ""))
+	assert.Equal(t, "Dry run of \"registry cleanup\", nothing was removed.\nImage \"sha256:foo\" would be removed (staging-foo-1, staging-foo-latest).\nDatabase \"base_foo\" would be removed.\n", buf.String())
+}
+
+func TestPlan_PrintWithViolations(t *testing.T) {
+	plan := NewPlan("database cleanup")
+	plan.AddViolation("the list of branches is empty")
+
+	var buf bytes.Buffer
+	assert.NoError(t, plan.Print(&buf, ""))
+	assert.Equal(t, "Dry run of \"database cleanup\", nothing was removed.\nSafety check failed, the cleanup would be refused: the list of branches is empty\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, plan.Print(&buf, "json"))
+	assert.JSONEq(t, `{"command": "database cleanup", "deletions": [], "violations": ["the list of branches is empty"]}`, buf.String())
+}
+
+func TestPlan_PrintJSON(t *testing.T) {
+	plan := NewPlan("database cleanup")
+
+	var buf bytes.Buffer
+	assert.NoError(t, plan.Print(&buf, "json"))
+	assert.JSONEq(t, `{"command": "database cleanup", "deletions": []}`, buf.String())
+}
+
+func TestPlan_PrintWithUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	assert.EqualError(t, NewPlan("database cleanup").Print(&buf, "xml"), "output format xml is not supported")
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,42 @@
+package naming
+
+// Lookup maps the names of resources back to the branch they were created for
+type Lookup struct {
+	branches []string
+}
+
+// NewLookup creates a lookup for the given branches
+func NewLookup(branches []string) *Lookup {
+	return &Lookup{branches: branches}
+}
+
+// BranchForNamespace returns the branch which belongs to the namespace with the given prefix
+func (l *Lookup) BranchForNamespace(prefix string, namespace string) (string, bool) {
+	return l.find(namespace, func(branchName string) string {
+		return Namespace(prefix, Namespace("", branchName))
+	})
+}
+
+// BranchForDatabase returns the branch which belongs to the database
+func (l *Lookup) BranchForDatabase(prefix string, database string) (string, bool) {
+	return l.find(database, func(branchName string) string {
+		return Database(prefix, branchName)
+	})
+}
+
+// BranchForImageTag returns the branch which belongs to the latest tag of an image
+func (l *Lookup) BranchForImageTag(tag string) (string, bool) {
+	return l.find(tag, func(branchName string) string {
+		return LatestImageTag(Namespace("", branchName))
+	})
+}
+
+func (l *Lookup) find(name string, nameFunc func(branchName string) string) (string, bool) {
+	for _, branchName := range l.branches {
+		if nameFunc(branchName) == name {
+			return branchName, true
+		}
+	}
+
+	return "", false
+}
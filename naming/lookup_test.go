@@ -0,0 +1,82 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupBranchForNamespace(t *testing.T) {
+	lookup := NewLookup([]string{"master", "feature/jira-123_foo"})
+
+	branchName, found := lookup.BranchForNamespace("", "feature-jira-123-foo")
+
+	assert.True(t, found)
+	assert.Equal(t, "feature/jira-123_foo", branchName)
+
+	_, found = lookup.BranchForNamespace("", "feature-jira-124-foo")
+
+	assert.False(t, found)
+
+	branchName, found = lookup.BranchForNamespace("app", "app-feature-jira-123-foo")
+
+	assert.True(t, found)
+	assert.Equal(t, "feature/jira-123_foo", branchName)
+
+	_, found = lookup.BranchForNamespace("app", "feature-jira-123-foo")
+
+	assert.False(t, found)
+}
+
+func TestLookupBranchForTruncatedNamespace(t *testing.T) {
+	longBranchName := "feature/" + strings.Repeat("a", 80)
+	lookup := NewLookup([]string{"master", longBranchName})
+
+	branchName, found := lookup.BranchForNamespace("app", Namespace("app", Namespace("", longBranchName)))
+
+	assert.True(t, found)
+	assert.Equal(t, longBranchName, branchName)
+}
+
+func TestLookupBranchForTruncatedDatabase(t *testing.T) {
+	longBranchName := "feature/" + strings.Repeat("a", 80)
+	lookup := NewLookup([]string{"master", longBranchName})
+
+	branchName, found := lookup.BranchForDatabase("branch_", Database("branch_", longBranchName))
+
+	assert.True(t, found)
+	assert.Equal(t, longBranchName, branchName)
+
+	_, found = lookup.BranchForDatabase("branch_", Database("branch_", longBranchName+"b"))
+
+	assert.False(t, found)
+}
+
+func TestLookupBranchForDatabaseWithMixedCase(t *testing.T) {
+	longBranchName := "Feature/" + strings.Repeat("A", 80)
+	lookup := NewLookup([]string{"master", "feature-x", strings.ToLower(longBranchName)})
+
+	branchName, found := lookup.BranchForDatabase("branch_", Database("branch_", "Feature-X"))
+
+	assert.True(t, found)
+	assert.Equal(t, "feature-x", branchName)
+
+	branchName, found = lookup.BranchForDatabase("branch_", Database("branch_", longBranchName))
+
+	assert.True(t, found)
+	assert.Equal(t, strings.ToLower(longBranchName), branchName)
+}
+
+func TestLookupBranchForImageTag(t *testing.T) {
+	lookup := NewLookup([]string{"master", "feature/foobar"})
+
+	branchName, found := lookup.BranchForImageTag("staging-feature-foobar-latest")
+
+	assert.True(t, found)
+	assert.Equal(t, "feature/foobar", branchName)
+
+	_, found = lookup.BranchForImageTag("staging-feature-barfoo-latest")
+
+	assert.False(t, found)
+}
@@ -0,0 +1,85 @@
+package naming
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+const (
+	// maxNamespaceLength is the maximum length of a DNS-1123 label
+	maxNamespaceLength = 63
+	// maxDatabaseLength keeps some space to the 64 characters of a MySQL identifier
+	maxDatabaseLength = 60
+	// maxImageTagLength is the maximum length of a docker tag
+	maxImageTagLength = 128
+
+	hashLength = 8
+
+	stagingEnvironment    = "staging"
+	productionEnvironment = "production"
+)
+
+var invalidNamespaceChars = regexp.MustCompile("[^a-z0-9-]")
+var invalidDatabaseChars = regexp.MustCompile("[^A-Za-z0-9_-]")
+var invalidImageTagChars = regexp.MustCompile("[^a-z0-9_.-]")
+
+// Namespace converts a branch name into a valid DNS-1123 label, which is used for the kubernetes namespace
+// e.g. feature/JIRA-123_foo is converted to feature-jira-123-foo, a prefix is part of the label so that the
+// truncated name including the prefix still fits
+func Namespace(prefix string, branchName string) string {
+	name := invalidNamespaceChars.ReplaceAllString(strings.ToLower(branchName), "-")
+	name = strings.Trim(name, "-")
+	original := branchName
+
+	if prefix != "" && name != "" {
+		name = prefix + "-" + name
+		original = prefix + "-" + branchName
+	}
+
+	return truncate(name, original, maxNamespaceLength, "-")
+}
+
+// Database returns the database name of a branch, invalid characters of a MySQL identifier are replaced with an underscore,
+// the branch is lower cased like the branches of the providers so that the name is the same for Feature-X and feature-x
+func Database(prefix string, branchName string) string {
+	original := prefix + strings.ToLower(branchName)
+	name := invalidDatabaseChars.ReplaceAllString(original, "_")
+
+	return truncate(name, original, maxDatabaseLength, "_")
+}
+
+// LatestImageTag returns the docker tag which marks the latest image of an environment
+func LatestImageTag(namespace string) string {
+	switch namespace {
+	case stagingEnvironment:
+		return "staging-latest"
+	case productionEnvironment:
+		return "latest"
+	}
+
+	return ImageTag("staging-" + namespace + "-latest")
+}
+
+// ImageTag converts a name into a valid docker tag, which must not start with a period or a dash
+func ImageTag(name string) string {
+	tag := invalidImageTagChars.ReplaceAllString(strings.ToLower(name), "-")
+	tag = strings.TrimLeft(tag, ".-")
+
+	return truncate(tag, name, maxImageTagLength, "-")
+}
+
+// truncate shortens a name to the max length, a hash of the original name is appended so that the result stays unique
+func truncate(name string, original string, maxLength int, separator string) string {
+	if len(name) <= maxLength {
+		return name
+	}
+
+	sum := sha1.Sum([]byte(original))
+	hash := hex.EncodeToString(sum[:])[:hashLength]
+
+	shortened := strings.TrimRight(name[:maxLength-hashLength-len(separator)], "-_.")
+
+	return shortened + separator + hash
+}
@@ -0,0 +1,69 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace(t *testing.T) {
+	for branchName, expected := range map[string]string{
+		"foobar":               "foobar",
+		"Feature-Foobar":       "feature-foobar",
+		"feature/JIRA-123_foo": "feature-jira-123-foo",
+		"-foo.bar-":            "foo-bar",
+		"":                     "",
+	} {
+		assert.Equal(t, expected, Namespace("", branchName))
+	}
+}
+
+func TestNamespaceIsTruncatedWithHash(t *testing.T) {
+	branchName := "feature/" + strings.Repeat("a", 80)
+
+	name := Namespace("", branchName)
+
+	assert.Len(t, name, maxNamespaceLength)
+	assert.Equal(t, "feature-"+strings.Repeat("a", 46)+"-", name[:55])
+	assert.Equal(t, name, Namespace("", branchName))
+	assert.NotEqual(t, name, Namespace("", branchName+"b"))
+}
+
+func TestNamespaceWithPrefix(t *testing.T) {
+	assert.Equal(t, "app-feature-jira-123-foo", Namespace("app", "feature/JIRA-123_foo"))
+	assert.Equal(t, "", Namespace("app", ""))
+
+	branchName := "feature/" + strings.Repeat("a", 60)
+
+	name := Namespace("app", branchName)
+
+	assert.Len(t, name, maxNamespaceLength)
+	assert.Equal(t, "app-feature-"+strings.Repeat("a", 42)+"-", name[:55])
+	assert.NotEqual(t, Namespace("", branchName)[55:], name[55:])
+}
+
+func TestDatabase(t *testing.T) {
+	assert.Equal(t, "branch_foobar", Database("branch_", "foobar"))
+	assert.Equal(t, "branch_feature_jira-123_foo", Database("branch_", "feature/jira-123_foo"))
+
+	name := Database("branch_", strings.Repeat("a", 80))
+
+	assert.Len(t, name, maxDatabaseLength)
+	assert.NotEqual(t, name, Database("branch_", strings.Repeat("a", 81)))
+}
+
+func TestLatestImageTag(t *testing.T) {
+	for namespace, expected := range map[string]string{
+		"staging":    "staging-latest",
+		"production": "latest",
+		"foobar":     "staging-foobar-latest",
+	} {
+		assert.Equal(t, expected, LatestImageTag(namespace))
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	assert.Equal(t, "v1.0_foo-bar", ImageTag(".V1.0_foo/bar"))
+	assert.Len(t, ImageTag(strings.Repeat("a", 200)), maxImageTagLength)
+}
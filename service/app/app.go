@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"kube-helper/loader"
+	"kube-helper/naming"
 	"os"
 	"strings"
 
@@ -32,6 +33,8 @@ var clock utilClock.Clock = new(utilClock.RealClock)
 var replaceVariablesInFile loader.ReplaceFunc = loader.ReplaceVariablesInFile
 var writer io.Writer = os.Stdout
 var kindServiceCreator = kind.NewKind
+var offlineKindServiceCreator = kind.NewOfflineKind
+var fileSystem = afero.NewOsFs()
 
 type ApplicationServiceInterface interface {
 	DeleteByNamespace() error
@@ -79,10 +82,7 @@ func NewApplicationService(namespace string, config loader.Config) (ApplicationS
 	}
 
 	a.clientSet = clientSet
-	a.prefixedNamespace = namespace
-	if config.Namespace.Prefix != "" {
-		a.prefixedNamespace = config.Namespace.Prefix + "-" + namespace
-	}
+	a.prefixedNamespace = getPrefixedNamespace(namespace, config)
 	a.namespace = namespace
 	a.config = config
 	a.dnsService = dnsService
@@ -91,6 +91,10 @@ func NewApplicationService(namespace string, config loader.Config) (ApplicationS
 	return a, nil
 }
 
+func getPrefixedNamespace(namespace string, config loader.Config) string {
+	return naming.Namespace(config.Namespace.Prefix, namespace)
+}
+
 const namespaceNameFmt = "[a-z0-9]([-a-z0-9]*[a-z0-9])?"
 
 var namespaceNameRegexp = regexp.MustCompile("^" + namespaceNameFmt + "$")
@@ -405,7 +409,8 @@ func (a *applicationService) waitForStaticIPToBeDeleted(projectID string, addres
 }
 
 func (a *applicationService) isValidNamespace() error {
-	if !namespaceNameRegexp.MatchString(a.prefixedNamespace) {
+	// the prefixed namespace is converted by the naming policy, invalid names should still be rejected
+	if !namespaceNameRegexp.MatchString(a.namespace) || !namespaceNameRegexp.MatchString(a.prefixedNamespace) {
 		return errors.New(validation.RegexError(namespaceNameFmt, "my-name", "123-abc"))
 	}
 	return nil
@@ -425,6 +430,20 @@ func (a *applicationService) createNamespace() error {
 
 	fmt.Fprintf(writer, "Namespace \"%s\" was generated\n", a.prefixedNamespace)
 
+	if !a.config.Namespace.NetworkPolicy.Isolate {
+		return nil
+	}
+
+	networkPolicy := kind.NewDefaultNetworkPolicy(a.prefixedNamespace, a.config.Namespace.NetworkPolicy)
+
+	_, err = a.clientSet.NetworkingV1().NetworkPolicies(a.prefixedNamespace).Create(networkPolicy)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(writer, "NetworkPolicy \"%s\" was generated\n", networkPolicy.Name)
+
 	return nil
 }
 
@@ -436,9 +455,15 @@ func (a *applicationService) applyFromConfig() error {
 		return err
 	}
 
-	kindService := kindServiceCreator(a.clientSet, imageService, a.config)
+	dynamicClientPool, err := serviceBuilder.GetDynamicClientPool(a.config)
+
+	if err != nil {
+		return err
+	}
+
+	kindService := kindServiceCreator(a.clientSet, dynamicClientPool, imageService, a.config)
 
-	err = replaceVariablesInFile(afero.NewOsFs(), a.config.KubernetesConfigFilepath, func(splitLines []string) error {
+	err = replaceVariablesInManifests(a.config.KubernetesConfigFilepath, func(splitLines []string) error {
 		return kindService.ApplyKind(a.prefixedNamespace, splitLines, a.namespace)
 	})
 
@@ -448,3 +473,22 @@ func (a *applicationService) applyFromConfig() error {
 
 	return kindService.CleanupKind(a.prefixedNamespace)
 }
+
+// replaceVariablesInManifests calls the function for every document of all manifest files in a deterministic order
+func replaceVariablesInManifests(paths loader.Paths, functionCall loader.Callable) error {
+	files, err := loader.ResolveManifestPaths(fileSystem, paths)
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		err = replaceVariablesInFile(fileSystem, file, functionCall)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"kube-helper/mocks"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,6 +28,8 @@ import (
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilClock "k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/dynamic"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	testingK8s "k8s.io/client-go/testing"
@@ -588,6 +591,14 @@ func TestApplicationService_DeleteByNamespaceWithError(t *testing.T) {
 	assert.EqualError(t, appService.DeleteByNamespace(), "explode")
 }
 
+func TestGetPrefixedNamespace(t *testing.T) {
+	config := loader.Config{Namespace: loader.Namespace{Prefix: "app"}}
+
+	assert.Equal(t, "foobar", getPrefixedNamespace("foobar", loader.Config{}))
+	assert.Equal(t, "app-foobar", getPrefixedNamespace("foobar", config))
+	assert.Len(t, getPrefixedNamespace(strings.Repeat("a", 63), config), 63)
+}
+
 func TestApplicationService_ApplyWithInvalidNamespace(t *testing.T) {
 
 	oldServiceBuilder := serviceBuilder
@@ -684,6 +695,7 @@ func TestApplicationService_ApplyWithEndpointsWithError(t *testing.T) {
 func TestApplicationService_ApplyWithEndpoints(t *testing.T) {
 
 	config := loader.Config{
+		KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"},
 		Endpoints: loader.Endpoints{
 			Enabled: true,
 		},
@@ -769,7 +781,7 @@ func TestApplicationService_ApplyWithEndpoints(t *testing.T) {
 
 func TestApplicationService_ApplyWithErrorForGetPods(t *testing.T) {
 
-	config := loader.Config{}
+	config := loader.Config{KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"}}
 	oldServiceBuilder := serviceBuilder
 	oldKindServiceCreator := kindServiceCreator
 
@@ -813,7 +825,7 @@ func TestApplicationService_ApplyWithErrorForGetPods(t *testing.T) {
 
 func TestApplicationService_ApplyWithErrorInReplace(t *testing.T) {
 
-	config := loader.Config{}
+	config := loader.Config{KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"}}
 	oldServiceBuilder := serviceBuilder
 	oldKindServiceCreator := kindServiceCreator
 
@@ -855,7 +867,7 @@ func TestApplicationService_ApplyWithErrorInReplace(t *testing.T) {
 
 func TestApplicationService_Apply(t *testing.T) {
 
-	config := loader.Config{}
+	config := loader.Config{KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"}}
 	oldServiceBuilder := serviceBuilder
 	oldKindServiceCreator := kindServiceCreator
 
@@ -896,6 +908,103 @@ func TestApplicationService_Apply(t *testing.T) {
 	assert.Contains(t, output, "There are 0 pods in the cluster\n")
 }
 
+func TestApplicationService_ApplyWithIsolatedNamespace(t *testing.T) {
+
+	config := loader.Config{
+		KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"},
+		Namespace: loader.Namespace{
+			NetworkPolicy: loader.NetworkPolicy{Isolate: true, AllowedCIDRs: []string{"130.211.0.0/22"}},
+		},
+	}
+	oldServiceBuilder := serviceBuilder
+	oldKindServiceCreator := kindServiceCreator
+	oldLReplaceFunc := replaceVariablesInFile
+
+	defer func() {
+		serviceBuilder = oldServiceBuilder
+		kindServiceCreator = oldKindServiceCreator
+		replaceVariablesInFile = oldLReplaceFunc
+	}()
+
+	imagesMock := new(mocks.ImagesInterface)
+	kindMock := new(mocks.KindInterface)
+	serviceBuilderMock, fakeClientSet := getBuilderMock(t, config, imagesMock)
+
+	kindServiceCreator = mockkindServiceCreator(t, fakeClientSet, imagesMock, config, kindMock)
+	serviceBuilder = serviceBuilderMock
+
+	appService, err := NewApplicationService("foobar", config)
+
+	assert.NoError(t, err)
+
+	replaceVariablesInFile = func(fileSystem afero.Fs, path string, functionCall loader.Callable) error {
+		return functionCall([]string{})
+	}
+
+	kindMock.On("ApplyKind", "foobar", []string{}, "foobar").Return(nil)
+	kindMock.On("CleanupKind", "foobar").Return(nil)
+
+	output := captureOutput(func() {
+		assert.NoError(t, appService.Apply())
+	})
+
+	assert.Contains(t, output, "Namespace \"foobar\" was generated\nNetworkPolicy \"default-isolation\" was generated\n")
+
+	networkPolicy, err := fakeClientSet.NetworkingV1().NetworkPolicies("foobar").Get("default-isolation", metaV1.GetOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, kind.NewDefaultNetworkPolicy("foobar", config.Namespace.NetworkPolicy), networkPolicy)
+}
+
+func TestApplicationService_ApplyWithManifestDirectory(t *testing.T) {
+
+	config := loader.Config{KubernetesConfigFilepath: loader.Paths{"manifests"}}
+	oldServiceBuilder := serviceBuilder
+	oldKindServiceCreator := kindServiceCreator
+	oldFileSystem := fileSystem
+	oldLReplaceFunc := replaceVariablesInFile
+
+	defer func() {
+		serviceBuilder = oldServiceBuilder
+		kindServiceCreator = oldKindServiceCreator
+		fileSystem = oldFileSystem
+		replaceVariablesInFile = oldLReplaceFunc
+	}()
+
+	fileSystem = afero.NewMemMapFs()
+	afero.WriteFile(fileSystem, "manifests/web/deployment.yml", []byte("web"), 0644)
+	afero.WriteFile(fileSystem, "manifests/api.yaml", []byte("api"), 0644)
+
+	imagesMock := new(mocks.ImagesInterface)
+	kindMock := new(mocks.KindInterface)
+	serviceBuilderMock, fakeClientSet := getBuilderMock(t, config, imagesMock)
+
+	kindServiceCreator = mockkindServiceCreator(t, fakeClientSet, imagesMock, config, kindMock)
+	serviceBuilder = serviceBuilderMock
+
+	appService, err := NewApplicationService("foobar", config)
+
+	assert.NoError(t, err)
+
+	var files []string
+
+	replaceVariablesInFile = func(fileSystem afero.Fs, path string, functionCall loader.Callable) error {
+		files = append(files, path)
+		return functionCall([]string{path})
+	}
+
+	kindMock.On("ApplyKind", "foobar", []string{"manifests/api.yaml"}, "foobar").Return(nil).Once()
+	kindMock.On("ApplyKind", "foobar", []string{"manifests/web/deployment.yml"}, "foobar").Return(nil).Once()
+	kindMock.On("CleanupKind", "foobar").Return(nil).Once()
+
+	captureOutput(func() {
+		assert.NoError(t, appService.Apply())
+	})
+
+	assert.Equal(t, []string{"manifests/api.yaml", "manifests/web/deployment.yml"}, files)
+	kindMock.AssertExpectations(t)
+}
+
 func TestApplicationService_ApplyWithErrorForImageService(t *testing.T) {
 
 	config := loader.Config{}
@@ -935,6 +1044,7 @@ func TestApplicationService_ApplyWithErrorForImageService(t *testing.T) {
 func TestApplicationService_ApplyWithDNSAndErrorForLoadBalancerIp(t *testing.T) {
 
 	config := loader.Config{
+		KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"},
 		Cluster: loader.Cluster{
 			Type:      "gcp",
 			ProjectID: "testing",
@@ -992,6 +1102,7 @@ func TestApplicationService_ApplyWithDNSAndErrorForLoadBalancerIp(t *testing.T)
 func TestApplicationService_ApplyWithDNSAndErrorForWaitungOnLoadbalancerIp(t *testing.T) {
 
 	config := loader.Config{
+		KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"},
 		Cluster: loader.Cluster{
 			Type:      "gcp",
 			ProjectID: "testing",
@@ -1073,6 +1184,7 @@ func TestApplicationService_ApplyWithDNSAndErrorForWaitungOnLoadbalancerIp(t *te
 func TestApplicationService_ApplyWithDNSAndErrorForWaitungOnLoadbalancerIpWithGet(t *testing.T) {
 
 	config := loader.Config{
+		KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"},
 		Cluster: loader.Cluster{
 			Type:      "gcp",
 			ProjectID: "testing",
@@ -1163,6 +1275,7 @@ func TestApplicationService_ApplyWithDNSAndErrorForWaitungOnLoadbalancerIpWithGe
 func TestApplicationService_ApplyWithDNSAndErrorForWaitungOnLoadbalancerIpWithRetry(t *testing.T) {
 
 	config := loader.Config{
+		KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"},
 		Cluster: loader.Cluster{
 			Type:      "gcp",
 			ProjectID: "testing",
@@ -1336,6 +1449,7 @@ func TestApplicationService_ApplyWithDNSAndErrorForDomainCreation(t *testing.T)
 func TestApplicationService_ApplyWithDNS(t *testing.T) {
 
 	config := loader.Config{
+		KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"},
 		Cluster: loader.Cluster{
 			Type:      "gcp",
 			ProjectID: "testing",
@@ -1474,6 +1588,7 @@ func getBuilderMock(t *testing.T, config loader.Config, imageMock image.ImagesIn
 	}
 
 	serviceBuilderMock.On("GetClientSet", config).Return(fakeClientSet, nil)
+	serviceBuilderMock.On("GetDynamicClientPool", config).Return(new(dynamicFake.FakeClientPool), nil)
 	serviceBuilderMock.On("GetServiceManagementService").Return(serviceManagementService, nil)
 	serviceBuilderMock.On("GetDNSService").Return(dnsService, nil)
 	serviceBuilderMock.On("GetComputeService").Return(computeService, nil)
@@ -1493,12 +1608,18 @@ func captureOutput(f func()) string {
 	return buf.String()
 }
 
-func mockkindServiceCreator(t *testing.T, expectedClientSet kubernetes.Interface, expectedImagesService image.ImagesInterface, expectedConfig loader.Config, serviceMock kind.KindInterface) func(client kubernetes.Interface, imagesService image.ImagesInterface, config loader.Config) kind.KindInterface {
-	return func(client kubernetes.Interface, imagesService image.ImagesInterface, config loader.Config) kind.KindInterface {
+func mockkindServiceCreator(t *testing.T, expectedClientSet kubernetes.Interface, expectedImagesService image.ImagesInterface, expectedConfig loader.Config, serviceMock kind.KindInterface) func(client kubernetes.Interface, dynamicClientPool dynamic.ClientPool, imagesService image.ImagesInterface, config loader.Config) kind.KindInterface {
+	return func(client kubernetes.Interface, dynamicClientPool dynamic.ClientPool, imagesService image.ImagesInterface, config loader.Config) kind.KindInterface {
 		assert.Equal(t, expectedConfig, config)
 		assert.Equal(t, expectedClientSet, client)
 		assert.Equal(t, expectedImagesService, imagesService)
 
+		if expectedClientSet == nil {
+			assert.Nil(t, dynamicClientPool)
+		} else {
+			assert.NotNil(t, dynamicClientPool)
+		}
+
 		return serviceMock
 	}
 }
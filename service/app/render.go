@@ -0,0 +1,77 @@
+package app
+
+import (
+	"kube-helper/loader"
+	"kube-helper/service/image"
+	"kube-helper/service/kind"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RenderFunc is called for every object of the manifests
+type RenderFunc func(object runtime.Object) error
+
+// RenderOptions controls whether the cluster is used to find the scope of the kinds
+type RenderOptions struct {
+	// Offline renders without a connection to the cluster, the scope of the kinds is taken from the client scheme
+	Offline bool
+	// AllowUnknownKinds renders kinds which are unknown to the client scheme as namespaced objects, only used offline
+	AllowUnknownKinds bool
+}
+
+// Render runs the manifests of the config through the same pipeline like Apply,
+// the decoded and changed objects are passed to the render func and nothing is sent to the cluster
+func Render(namespace string, config loader.Config, options RenderOptions, renderFunc RenderFunc) error {
+	imageService, err := serviceBuilder.GetImagesService()
+
+	if err != nil {
+		return err
+	}
+
+	kindService, err := getRenderKindService(imageService, config, options)
+
+	if err != nil {
+		return err
+	}
+
+	prefixedNamespace := getPrefixedNamespace(namespace, config)
+
+	return replaceVariablesInManifests(config.KubernetesConfigFilepath, func(splitLines []string) error {
+		objects, err := kindService.RenderKind(prefixedNamespace, splitLines, namespace)
+
+		if err != nil {
+			return err
+		}
+
+		for _, object := range objects {
+			err = renderFunc(object)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// getRenderKindService uses the discovery of the cluster, so unsupported kinds fail the same way like in Apply
+func getRenderKindService(imageService image.ImagesInterface, config loader.Config, options RenderOptions) (kind.KindInterface, error) {
+	if options.Offline {
+		return offlineKindServiceCreator(imageService, config, options.AllowUnknownKinds), nil
+	}
+
+	clientSet, err := serviceBuilder.GetClientSet(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClientPool, err := serviceBuilder.GetDynamicClientPool(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return kindServiceCreator(clientSet, dynamicClientPool, imageService, config), nil
+}
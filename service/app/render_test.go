@@ -0,0 +1,159 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"kube-helper/loader"
+	"kube-helper/mocks"
+	"kube-helper/service/image"
+	"kube-helper/service/kind"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRender(t *testing.T) {
+	config := loader.Config{
+		KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"},
+		Namespace: loader.Namespace{
+			Prefix: "app",
+		},
+	}
+
+	oldServiceBuilder := serviceBuilder
+	oldKindServiceCreator := kindServiceCreator
+	oldReplaceFunc := replaceVariablesInFile
+
+	defer func() {
+		serviceBuilder = oldServiceBuilder
+		kindServiceCreator = oldKindServiceCreator
+		replaceVariablesInFile = oldReplaceFunc
+	}()
+
+	imagesMock := new(mocks.ImagesInterface)
+	kindMock := new(mocks.KindInterface)
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	fakeClientSet := fake.NewSimpleClientset()
+	serviceBuilderMock.On("GetImagesService").Return(imagesMock, nil)
+	serviceBuilderMock.On("GetClientSet", config).Return(fakeClientSet, nil)
+	serviceBuilderMock.On("GetDynamicClientPool", config).Return(new(dynamicFake.FakeClientPool), nil)
+
+	serviceBuilder = serviceBuilderMock
+	kindServiceCreator = mockkindServiceCreator(t, fakeClientSet, imagesMock, config, kindMock)
+
+	replaceVariablesInFile = func(fileSystem afero.Fs, path string, functionCall loader.Callable) error {
+		assert.Equal(t, "kubernetes.yml", path)
+
+		err := functionCall([]string{"kind: Secret"})
+
+		if err != nil {
+			return err
+		}
+
+		return functionCall([]string{"kind: ConfigMap"})
+	}
+
+	secret := &coreV1.Secret{ObjectMeta: meta_v1.ObjectMeta{Name: "secret", Namespace: "app-foobar"}}
+	configMap := &coreV1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: "config", Namespace: "app-foobar"}}
+
+	kindMock.On("RenderKind", "app-foobar", []string{"kind: Secret"}, "foobar").Return([]runtime.Object{secret}, nil)
+	kindMock.On("RenderKind", "app-foobar", []string{"kind: ConfigMap"}, "foobar").Return([]runtime.Object{configMap}, nil)
+
+	var objects []runtime.Object
+
+	err := Render("foobar", config, RenderOptions{}, func(object runtime.Object) error {
+		objects = append(objects, object)
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []runtime.Object{secret, configMap}, objects)
+}
+
+func TestRenderWithErrorForRenderKind(t *testing.T) {
+	config := loader.Config{KubernetesConfigFilepath: loader.Paths{"kubernetes.yml"}}
+
+	oldServiceBuilder := serviceBuilder
+	oldOfflineKindServiceCreator := offlineKindServiceCreator
+	oldReplaceFunc := replaceVariablesInFile
+
+	defer func() {
+		serviceBuilder = oldServiceBuilder
+		offlineKindServiceCreator = oldOfflineKindServiceCreator
+		replaceVariablesInFile = oldReplaceFunc
+	}()
+
+	imagesMock := new(mocks.ImagesInterface)
+	kindMock := new(mocks.KindInterface)
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetImagesService").Return(imagesMock, nil)
+
+	serviceBuilder = serviceBuilderMock
+	offlineKindServiceCreator = func(imagesService image.ImagesInterface, expectedConfig loader.Config, allowUnknownKinds bool) kind.KindInterface {
+		assert.Equal(t, imagesMock, imagesService)
+		assert.Equal(t, config, expectedConfig)
+		assert.False(t, allowUnknownKinds)
+
+		return kindMock
+	}
+
+	replaceVariablesInFile = func(fileSystem afero.Fs, path string, functionCall loader.Callable) error {
+		return functionCall([]string{"kind: Pod"})
+	}
+
+	kindMock.On("RenderKind", "foobar", []string{"kind: Pod"}, "foobar").Return(nil, errors.New("kind Pod is not supported"))
+
+	err := Render("foobar", config, RenderOptions{Offline: true}, func(object runtime.Object) error {
+		t.Fatal("render func must not be called")
+
+		return nil
+	})
+
+	assert.EqualError(t, err, "kind Pod is not supported")
+}
+
+func TestRenderWithErrorForImagesService(t *testing.T) {
+	oldServiceBuilder := serviceBuilder
+
+	defer func() {
+		serviceBuilder = oldServiceBuilder
+	}()
+
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetImagesService").Return(nil, errors.New("explode"))
+
+	serviceBuilder = serviceBuilderMock
+
+	err := Render("foobar", loader.Config{}, RenderOptions{}, func(object runtime.Object) error {
+		return nil
+	})
+
+	assert.EqualError(t, err, "explode")
+}
+
+func TestRenderWithErrorForClientSet(t *testing.T) {
+	oldServiceBuilder := serviceBuilder
+
+	defer func() {
+		serviceBuilder = oldServiceBuilder
+	}()
+
+	serviceBuilderMock := new(mocks.ServiceBuilderInterface)
+	serviceBuilderMock.On("GetImagesService").Return(new(mocks.ImagesInterface), nil)
+	serviceBuilderMock.On("GetClientSet", loader.Config{}).Return(nil, errors.New("explode"))
+
+	serviceBuilder = serviceBuilderMock
+
+	err := Render("foobar", loader.Config{}, RenderOptions{}, func(object runtime.Object) error {
+		return nil
+	})
+
+	assert.EqualError(t, err, "explode")
+}
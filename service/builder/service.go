@@ -19,6 +19,7 @@ import (
 	"google.golang.org/api/servicemanagement/v1"
 	"google.golang.org/api/sqladmin/v1beta4"
 	"google.golang.org/api/storage/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/rest"
@@ -29,6 +30,7 @@ import (
 // ServiceBuilder API
 type ServiceBuilderInterface interface {
 	GetClientSet(config loader.Config) (kubernetes.Interface, error)
+	GetDynamicClientPool(config loader.Config) (dynamic.ClientPool, error)
 	GetDNSService() (*dns.Service, error)
 	GetSQLService() (*sqladmin.Service, error)
 	GetStorageService(bucketName string) (bucket.BucketServiceInterface, error)
@@ -46,18 +48,39 @@ func NewServiceBuilder() ServiceBuilderInterface {
 
 // GetClientSet returns the kubernetes client for local or gcp
 func (h *builder) GetClientSet(config loader.Config) (kubernetes.Interface, error) {
+	kubernetesConfig, err := h.getRestConfig(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(kubernetesConfig)
+}
+
+// GetDynamicClientPool returns the clients for kinds without a typed client, e.g. custom resources
+func (h *builder) GetDynamicClientPool(config loader.Config) (dynamic.ClientPool, error) {
+	kubernetesConfig, err := h.getRestConfig(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamic.NewDynamicClientPool(kubernetesConfig), nil
+}
+
+func (h *builder) getRestConfig(config loader.Config) (*rest.Config, error) {
 
 	switch clusterType := config.Cluster.Type; clusterType {
 	case "local":
-		return h.getClientSetForLocal()
+		return h.getRestConfigForLocal()
 	case "gcp":
 		fallthrough
 	default:
-		return h.getClientSetForGoogleCloudPlatform(config)
+		return h.getRestConfigForGoogleCloudPlatform(config)
 	}
 }
 
-func (h *builder) getClientSetForGoogleCloudPlatform(config loader.Config) (kubernetes.Interface, error) {
+func (h *builder) getRestConfigForGoogleCloudPlatform(config loader.Config) (*rest.Config, error) {
 
 	cService, err := h.getContainerService()
 
@@ -85,10 +108,10 @@ func (h *builder) getClientSetForGoogleCloudPlatform(config loader.Config) (kube
 
 	kubernetesConfig.TLSClientConfig.CAData = ca
 
-	return kubernetes.NewForConfig(kubernetesConfig)
+	return kubernetesConfig, nil
 }
 
-func (h *builder) getClientSetForLocal() (kubernetes.Interface, error) {
+func (h *builder) getRestConfigForLocal() (*rest.Config, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 	configOverrides := &clientcmd.ConfigOverrides{}
 	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
@@ -96,7 +119,7 @@ func (h *builder) getClientSetForLocal() (kubernetes.Interface, error) {
 	if err != nil {
 		return nil, errors.New("failed loading client config")
 	}
-	return kubernetes.NewForConfig(config)
+	return config, nil
 }
 
 func (h *builder) getContainerService() (*container.Service, error) {
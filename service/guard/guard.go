@@ -0,0 +1,89 @@
+package guard
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"kube-helper/loader"
+	"kube-helper/model"
+	"kube-helper/util"
+)
+
+const defaultBranch = "master"
+
+// CleanupGuardInterface protects the branch driven cleanup commands against an empty or truncated list of branches
+type CleanupGuardInterface interface {
+	CheckBranches(branches []string) error
+	CheckDeletions(deletions []string, total int) error
+}
+
+type cleanupGuard struct {
+	config loader.Cleanup
+	force  bool
+	plan   *model.Plan
+	writer io.Writer
+}
+
+// NewCleanupGuard is the constructor method and returns a service which implements the CleanupGuardInterface
+// if force is set, violated checks are only printed to the writer instead of returning an error,
+// on a dry run they are recorded in the plan so that the plan is still printed
+func NewCleanupGuard(config loader.Cleanup, force bool, plan *model.Plan, writer io.Writer) CleanupGuardInterface {
+	g := new(cleanupGuard)
+	g.config = config
+	g.force = force
+	g.plan = plan
+	g.writer = writer
+
+	return g
+}
+
+// CheckBranches refuses a list of branches which is empty or does not contain the default branch
+func (g *cleanupGuard) CheckBranches(branches []string) error {
+	if len(branches) == 0 {
+		return g.violation("the list of branches is empty")
+	}
+
+	branchName := g.defaultBranch()
+
+	if !util.Contains(branches, branchName) {
+		return g.violation(fmt.Sprintf("the default branch \"%s\" is missing in the list of branches", branchName))
+	}
+
+	return nil
+}
+
+// CheckDeletions refuses more deletions than the configured maximum count or percentage of total
+func (g *cleanupGuard) CheckDeletions(deletions []string, total int) error {
+	if g.config.MaxDeletions > 0 && len(deletions) > g.config.MaxDeletions {
+		return g.violation(fmt.Sprintf("%d deletions exceed the maximum of %d per run (%s)", len(deletions), g.config.MaxDeletions, strings.Join(deletions, ", ")))
+	}
+
+	if g.config.MaxDeletionPercentage > 0 && total > 0 && len(deletions)*100 > g.config.MaxDeletionPercentage*total {
+		return g.violation(fmt.Sprintf("%d of %d deletions exceed the maximum of %d%% per run (%s)", len(deletions), total, g.config.MaxDeletionPercentage, strings.Join(deletions, ", ")))
+	}
+
+	return nil
+}
+
+func (g *cleanupGuard) defaultBranch() string {
+	if g.config.DefaultBranch == "" {
+		return defaultBranch
+	}
+
+	return strings.ToLower(g.config.DefaultBranch)
+}
+
+func (g *cleanupGuard) violation(reason string) error {
+	if g.force {
+		fmt.Fprintf(g.writer, "Safety check skipped because of --force: %s\n", reason)
+		return nil
+	}
+
+	if g.plan != nil {
+		g.plan.AddViolation(reason)
+		return nil
+	}
+
+	return fmt.Errorf("refusing to cleanup, %s (use --force to skip this check)", reason)
+}
@@ -0,0 +1,66 @@
+package guard
+
+import (
+	"bytes"
+	"testing"
+
+	"kube-helper/loader"
+	"kube-helper/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanupGuard_CheckBranches(t *testing.T) {
+	var buf bytes.Buffer
+	guard := NewCleanupGuard(loader.Cleanup{}, false, nil, &buf)
+
+	assert.NoError(t, guard.CheckBranches([]string{"master", "feature"}))
+	assert.EqualError(t, guard.CheckBranches([]string{}), "refusing to cleanup, the list of branches is empty (use --force to skip this check)")
+	assert.EqualError(t, guard.CheckBranches([]string{"feature"}), "refusing to cleanup, the default branch \"master\" is missing in the list of branches (use --force to skip this check)")
+	assert.Empty(t, buf.String())
+}
+
+func TestCleanupGuard_CheckBranchesWithConfiguredDefaultBranch(t *testing.T) {
+	var buf bytes.Buffer
+	guard := NewCleanupGuard(loader.Cleanup{DefaultBranch: "Develop"}, false, nil, &buf)
+
+	assert.NoError(t, guard.CheckBranches([]string{"develop"}))
+	assert.EqualError(t, guard.CheckBranches([]string{"master"}), "refusing to cleanup, the default branch \"develop\" is missing in the list of branches (use --force to skip this check)")
+}
+
+func TestCleanupGuard_CheckDeletions(t *testing.T) {
+	var buf bytes.Buffer
+
+	guard := NewCleanupGuard(loader.Cleanup{}, false, nil, &buf)
+	assert.NoError(t, guard.CheckDeletions([]string{"a", "b", "c"}, 3))
+
+	guard = NewCleanupGuard(loader.Cleanup{MaxDeletions: 2}, false, nil, &buf)
+	assert.NoError(t, guard.CheckDeletions([]string{"a", "b"}, 10))
+	assert.EqualError(t, guard.CheckDeletions([]string{"a", "b", "c"}, 10), "refusing to cleanup, 3 deletions exceed the maximum of 2 per run (a, b, c) (use --force to skip this check)")
+
+	guard = NewCleanupGuard(loader.Cleanup{MaxDeletionPercentage: 50}, false, nil, &buf)
+	assert.NoError(t, guard.CheckDeletions([]string{"a", "b"}, 4))
+	assert.EqualError(t, guard.CheckDeletions([]string{"a", "b", "c"}, 4), "refusing to cleanup, 3 of 4 deletions exceed the maximum of 50% per run (a, b, c) (use --force to skip this check)")
+
+	assert.Empty(t, buf.String())
+}
+
+func TestCleanupGuard_Force(t *testing.T) {
+	var buf bytes.Buffer
+	guard := NewCleanupGuard(loader.Cleanup{MaxDeletions: 1}, true, nil, &buf)
+
+	assert.NoError(t, guard.CheckBranches([]string{}))
+	assert.NoError(t, guard.CheckDeletions([]string{"a", "b"}, 2))
+	assert.Equal(t, "Safety check skipped because of --force: the list of branches is empty\nSafety check skipped because of --force: 2 deletions exceed the maximum of 1 per run (a, b)\n", buf.String())
+}
+
+func TestCleanupGuard_DryRun(t *testing.T) {
+	var buf bytes.Buffer
+	plan := model.NewPlan("application cleanup")
+	guard := NewCleanupGuard(loader.Cleanup{MaxDeletions: 1}, false, plan, &buf)
+
+	assert.NoError(t, guard.CheckBranches([]string{}))
+	assert.NoError(t, guard.CheckDeletions([]string{"a", "b"}, 2))
+	assert.Equal(t, []string{"the list of branches is empty", "2 deletions exceed the maximum of 1 per run (a, b)"}, plan.Violations)
+	assert.Empty(t, buf.String())
+}
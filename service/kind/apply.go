@@ -1,6 +1,8 @@
 package kind
 
 import (
+	"crypto/sha1"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -8,245 +10,291 @@ import (
 	"kube-helper/util"
 
 	"kube-helper/model"
+	"kube-helper/naming"
 
 	apps "k8s.io/api/apps/v1"
-	batch "k8s.io/api/batch/v1beta1"
+	batchV1 "k8s.io/api/batch/v1"
 	coreV1 "k8s.io/api/core/v1"
-	extensions "k8s.io/api/extensions/v1beta1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+const specHashAnnotation = "kube-helper/spec-hash"
+
 func (k *kindService) ApplyKind(kubernetesNamespace string, fileLines []string, namespaceWithoutPrefix string) error {
 
-	fileContent, groupVersionKind, err := k.decoder.Decode([]byte(strings.Join(fileLines, "\n")), nil, nil)
+	objects, err := k.RenderKind(kubernetesNamespace, fileLines, namespaceWithoutPrefix)
 
 	if err != nil {
 		return err
 	}
-	switch groupVersionKind.Kind {
-	case "Secret":
-		return k.upsertSecrets(kubernetesNamespace, fileContent.(*coreV1.Secret))
-	case "ConfigMap":
-		return k.upsertConfigMap(kubernetesNamespace, fileContent.(*coreV1.ConfigMap))
-	case "Service":
-		return k.upsertService(kubernetesNamespace, fileContent.(*coreV1.Service))
-	case "Deployment":
-		return k.upsertDeployment(kubernetesNamespace, fileContent.(*apps.Deployment), namespaceWithoutPrefix)
-	case "Ingress":
-		return k.upsertIngress(kubernetesNamespace, fileContent.(*extensions.Ingress))
-	case "CronJob":
-		return k.upsertCronJob(kubernetesNamespace, fileContent.(*batch.CronJob), namespaceWithoutPrefix)
-	case "PersistentVolume":
-		return k.upsertPersistentVolume(fileContent.(*coreV1.PersistentVolume))
-	case "PersistentVolumeClaim":
-		return k.upsertPersistentVolumeClaim(kubernetesNamespace, fileContent.(*coreV1.PersistentVolumeClaim))
+
+	for _, object := range objects {
+		err = k.applyObject(kubernetesNamespace, object)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyObject uses the typed clients for the kinds which need special handling, all other kinds are rendered unstructured
+func (k *kindService) applyObject(kubernetesNamespace string, object runtime.Object) error {
+	switch object := object.(type) {
+	case *coreV1.Service:
+		return k.upsertService(kubernetesNamespace, object)
+	case *apps.Deployment:
+		return k.upsertDeployment(kubernetesNamespace, object)
+	case *apps.StatefulSet:
+		return k.upsertStatefulSet(kubernetesNamespace, object)
+	case *batchV1.Job:
+		return k.upsertJob(kubernetesNamespace, object)
+	case *coreV1.PersistentVolumeClaim:
+		return k.upsertPersistentVolumeClaim(kubernetesNamespace, object)
+	case *unstructured.Unstructured:
+		return k.upsertUnstructured(object)
 	default:
-		return fmt.Errorf("kind %s is not supported", fileContent.GetObjectKind().GroupVersionKind().Kind)
+		return fmt.Errorf("kind %s is not supported", object.GetObjectKind().GroupVersionKind().Kind)
 	}
 }
 
-func (k *kindService) upsertSecrets(kubernetesNamespace string, secret *coreV1.Secret) error {
-	_, err := k.clientSet.CoreV1().Secrets(kubernetesNamespace).Get(secret.Name, metaV1.GetOptions{})
+func (k *kindService) upsertDeployment(kubernetesNamespace string, deployment *apps.Deployment) error {
+
+	existingDeployment, err := k.clientSet.AppsV1().Deployments(kubernetesNamespace).Get(deployment.Name, metaV1.GetOptions{})
 
 	if err != nil {
-		_, err := k.clientSet.CoreV1().Secrets(kubernetesNamespace).Create(secret)
+		err = setLastAppliedAnnotation(deployment)
 
 		if err != nil {
 			return err
 		}
 
-		k.usedKind.secret = append(k.usedKind.secret, secret.Name)
+		_, err = k.clientSet.AppsV1().Deployments(kubernetesNamespace).Create(deployment)
 
-		fmt.Fprintf(writer, "Secret \"%s\" was generated.\n", secret.Name)
+		if err != nil {
+			return getApplyError("Deployment", deployment.Name, err)
+		}
+
+		k.usedKind.deployment = append(k.usedKind.deployment, deployment.Name)
+
+		fmt.Fprintf(writer, "Deployment \"%s\" was generated.\n", deployment.Name)
 
 		return nil
 	}
 
-	_, err = k.clientSet.CoreV1().Secrets(kubernetesNamespace).Update(secret)
+	hasAutoscaler, err := k.hasHorizontalPodAutoscaler(kubernetesNamespace, "Deployment", deployment.Name)
 
 	if err != nil {
 		return err
 	}
 
-	k.usedKind.secret = append(k.usedKind.secret, secret.Name)
-
-	fmt.Fprintf(writer, "Secret \"%s\" was updated.\n", secret.Name)
-
-	return nil
-}
-
-func (k *kindService) upsertCronJob(kubernetesNamespace string, cronJob *batch.CronJob, namespaceWithoutPrefix string) error {
+	if hasAutoscaler {
+		// the replicas are managed by the autoscaler and should not be reset on every apply
+		deployment.Spec.Replicas = existingDeployment.Spec.Replicas
+	}
 
-	err := k.setImageForContainer(cronJob.Annotations, cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers, namespaceWithoutPrefix)
+	patch, err := getApplyPatch(deployment, existingDeployment, apps.Deployment{})
 
 	if err != nil {
 		return err
 	}
 
-	_, err = k.clientSet.BatchV1beta1().CronJobs(kubernetesNamespace).Get(cronJob.Name, metaV1.GetOptions{})
-
-	if err != nil {
-		_, err := k.clientSet.BatchV1beta1().CronJobs(kubernetesNamespace).Create(cronJob)
-
-		if err != nil {
-			return err
-		}
-
-		k.usedKind.cronJob = append(k.usedKind.cronJob, cronJob.Name)
-
-		fmt.Fprintf(writer, "CronJob \"%s\" was generated.\n", cronJob.Name)
-
-		return nil
-	}
-	_, err = k.clientSet.BatchV1beta1().CronJobs(kubernetesNamespace).Update(cronJob)
+	_, err = k.clientSet.AppsV1().Deployments(kubernetesNamespace).Patch(deployment.Name, types.StrategicMergePatchType, patch)
 
 	if err != nil {
-		return err
+		return getApplyError("Deployment", deployment.Name, err)
 	}
 
-	k.usedKind.cronJob = append(k.usedKind.cronJob, cronJob.Name)
+	k.usedKind.deployment = append(k.usedKind.deployment, deployment.Name)
 
-	fmt.Fprintf(writer, "CronJob \"%s\" was updated.\n", cronJob.Name)
+	fmt.Fprintf(writer, "Deployment \"%s\" was updated.\n", deployment.Name)
 
 	return nil
 }
 
-func (k *kindService) upsertDeployment(kubernetesNamespace string, deployment *apps.Deployment, namespaceWithoutPrefix string) error {
+func (k *kindService) upsertStatefulSet(kubernetesNamespace string, statefulSet *apps.StatefulSet) error {
 
-	err := k.setImageForContainer(deployment.Annotations, deployment.Spec.Template.Spec.Containers, namespaceWithoutPrefix)
+	existingStatefulSet, err := k.clientSet.AppsV1().StatefulSets(kubernetesNamespace).Get(statefulSet.Name, metaV1.GetOptions{})
 
 	if err != nil {
-		return err
-	}
+		err = setLastAppliedAnnotation(statefulSet)
 
-	_, err = k.clientSet.AppsV1().Deployments(kubernetesNamespace).Get(deployment.Name, metaV1.GetOptions{})
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		_, err := k.clientSet.AppsV1().Deployments(kubernetesNamespace).Create(deployment)
+		_, err = k.clientSet.AppsV1().StatefulSets(kubernetesNamespace).Create(statefulSet)
 
 		if err != nil {
-			return err
+			return getApplyError("StatefulSet", statefulSet.Name, err)
 		}
 
-		k.usedKind.deployment = append(k.usedKind.deployment, deployment.Name)
+		k.usedKind.statefulSet = append(k.usedKind.statefulSet, statefulSet.Name)
 
-		fmt.Fprintf(writer, "Deployment \"%s\" was generated.\n", deployment.Name)
+		fmt.Fprintf(writer, "StatefulSet \"%s\" was generated.\n", statefulSet.Name)
 
 		return nil
 	}
 
-	_, err = k.clientSet.AppsV1().Deployments(kubernetesNamespace).Update(deployment)
+	// only replicas, template and update strategy can be changed, keep the immutable fields of the existing one
+	statefulSet.Spec.Selector = existingStatefulSet.Spec.Selector
+	statefulSet.Spec.VolumeClaimTemplates = existingStatefulSet.Spec.VolumeClaimTemplates
+	statefulSet.Spec.ServiceName = existingStatefulSet.Spec.ServiceName
+	statefulSet.Spec.PodManagementPolicy = existingStatefulSet.Spec.PodManagementPolicy
+
+	patch, err := getApplyPatch(statefulSet, existingStatefulSet, apps.StatefulSet{})
 
 	if err != nil {
 		return err
 	}
 
-	k.usedKind.deployment = append(k.usedKind.deployment, deployment.Name)
+	_, err = k.clientSet.AppsV1().StatefulSets(kubernetesNamespace).Patch(statefulSet.Name, types.StrategicMergePatchType, patch)
 
-	fmt.Fprintf(writer, "Deployment \"%s\" was updated.\n", deployment.Name)
+	if err != nil {
+		return getApplyError("StatefulSet", statefulSet.Name, err)
+	}
+
+	k.usedKind.statefulSet = append(k.usedKind.statefulSet, statefulSet.Name)
+
+	fmt.Fprintf(writer, "StatefulSet \"%s\" was updated.\n", statefulSet.Name)
 
 	return nil
 }
 
-func (k *kindService) upsertService(kubernetesNamespace string, service *coreV1.Service) error {
+// upsertJob creates the job, because the pod template of a job is immutable an existing job is recreated if the spec changed
+func (k *kindService) upsertJob(kubernetesNamespace string, job *batchV1.Job) error {
 
-	existingService, err := k.clientSet.CoreV1().Services(kubernetesNamespace).Get(service.Name, metaV1.GetOptions{})
+	specHash, err := getSpecHash(job.Spec)
 
 	if err != nil {
+		return err
+	}
 
-		_, err := k.clientSet.CoreV1().Services(kubernetesNamespace).Create(service)
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
 
-		if err != nil {
-			return err
-		}
+	job.Annotations[specHashAnnotation] = specHash
 
-		k.usedKind.service = append(k.usedKind.service, service.Name)
+	existingJob, err := k.clientSet.BatchV1().Jobs(kubernetesNamespace).Get(job.Name, metaV1.GetOptions{})
 
-		fmt.Fprintf(writer, "Service \"%s\" was generated.\n", service.Name)
+	message := "Job \"%s\" was generated.\n"
 
-		return nil
-	}
+	if err == nil {
+		if existingJob.Annotations[specHashAnnotation] == specHash {
+			k.usedKind.job = append(k.usedKind.job, job.Name)
 
-	service.ResourceVersion = existingService.ResourceVersion
-	service.Spec.ClusterIP = existingService.Spec.ClusterIP
+			fmt.Fprintf(writer, "Job \"%s\" is unchanged.\n", job.Name)
 
-	if _, ok := service.Annotations["tourstream.eu/ingress"]; ok {
-		// TODO add better check which port is which, for now take the same ports like before so that the backend still works with it
-		service.Spec.Ports = existingService.Spec.Ports
+			return nil
+		}
+
+		err = k.clientSet.BatchV1().Jobs(kubernetesNamespace).Delete(job.Name, getDeleteOptionsWithDependents())
+
+		if err != nil {
+			return err
+		}
+
+		message = "Job \"%s\" was recreated.\n"
 	}
 
-	_, err = k.clientSet.CoreV1().Services(kubernetesNamespace).Update(service)
+	_, err = k.clientSet.BatchV1().Jobs(kubernetesNamespace).Create(job)
 
 	if err != nil {
-		return err
+		return getApplyError("Job", job.Name, err)
 	}
 
-	k.usedKind.service = append(k.usedKind.service, service.Name)
+	k.usedKind.job = append(k.usedKind.job, job.Name)
 
-	fmt.Fprintf(writer, "Service \"%s\" was updated.\n", service.Name)
+	fmt.Fprintf(writer, message, job.Name)
 
 	return nil
 }
 
-func (k *kindService) upsertConfigMap(kubernetesNamespace string, configMap *coreV1.ConfigMap) error {
-
-	_, err := k.clientSet.CoreV1().ConfigMaps(kubernetesNamespace).Get(configMap.Name, metaV1.GetOptions{})
+func getSpecHash(spec interface{}) (string, error) {
+	content, err := json.Marshal(spec)
 
 	if err != nil {
+		return "", err
+	}
 
-		_, err := k.clientSet.CoreV1().ConfigMaps(kubernetesNamespace).Create(configMap)
-
-		if err != nil {
-			return err
-		}
-
-		k.usedKind.configMap = append(k.usedKind.configMap, configMap.Name)
+	return fmt.Sprintf("%x", sha1.Sum(content)), nil
+}
 
-		fmt.Fprintf(writer, "ConfigMap \"%s\" was generated.\n", configMap.Name)
+// getDeleteOptionsWithDependents removes the pods of a job as well
+func getDeleteOptionsWithDependents() *metaV1.DeleteOptions {
+	propagationPolicy := metaV1.DeletePropagationBackground
 
-		return nil
-	}
+	return &metaV1.DeleteOptions{PropagationPolicy: &propagationPolicy}
+}
 
-	_, err = k.clientSet.CoreV1().ConfigMaps(kubernetesNamespace).Update(configMap)
+func (k *kindService) hasHorizontalPodAutoscaler(kubernetesNamespace string, kind string, name string) (bool, error) {
+	list, err := k.clientSet.AutoscalingV1().HorizontalPodAutoscalers(kubernetesNamespace).List(metaV1.ListOptions{})
 
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	k.usedKind.configMap = append(k.usedKind.configMap, configMap.Name)
-
-	fmt.Fprintf(writer, "ConfigMap \"%s\" was updated.\n", configMap.Name)
+	for _, autoscaler := range list.Items {
+		if autoscaler.Spec.ScaleTargetRef.Kind == kind && autoscaler.Spec.ScaleTargetRef.Name == name {
+			return true, nil
+		}
+	}
 
-	return nil
+	return false, nil
 }
 
-func (k *kindService) upsertPersistentVolume(persistentVolume *coreV1.PersistentVolume) error {
+func (k *kindService) upsertService(kubernetesNamespace string, service *coreV1.Service) error {
 
-	_, err := k.clientSet.CoreV1().PersistentVolumes().Get(persistentVolume.Name, metaV1.GetOptions{})
+	existingService, err := k.clientSet.CoreV1().Services(kubernetesNamespace).Get(service.Name, metaV1.GetOptions{})
 
 	if err != nil {
-		_, err := k.clientSet.CoreV1().PersistentVolumes().Create(persistentVolume)
+
+		err = setLastAppliedAnnotation(service)
 
 		if err != nil {
 			return err
 		}
 
-		k.usedKind.persistentVolume = append(k.usedKind.persistentVolume, persistentVolume.Name)
+		_, err = k.clientSet.CoreV1().Services(kubernetesNamespace).Create(service)
+
+		if err != nil {
+			return getApplyError("Service", service.Name, err)
+		}
+
+		k.usedKind.service = append(k.usedKind.service, service.Name)
 
-		fmt.Fprintf(writer, "PersistentVolume \"%s\" was generated.\n", persistentVolume.Name)
+		fmt.Fprintf(writer, "Service \"%s\" was generated.\n", service.Name)
 
 		return nil
 	}
 
-	_, err = k.clientSet.CoreV1().PersistentVolumes().Update(persistentVolume)
+	service.Spec.ClusterIP = existingService.Spec.ClusterIP
+
+	if _, ok := service.Annotations["tourstream.eu/ingress"]; ok {
+		// TODO add better check which port is which, for now take the same ports like before so that the backend still works with it
+		service.Spec.Ports = existingService.Spec.Ports
+	}
+
+	patch, err := getApplyPatch(service, existingService, coreV1.Service{})
 
 	if err != nil {
 		return err
 	}
 
-	k.usedKind.persistentVolume = append(k.usedKind.persistentVolume, persistentVolume.Name)
+	_, err = k.clientSet.CoreV1().Services(kubernetesNamespace).Patch(service.Name, types.StrategicMergePatchType, patch)
 
-	fmt.Fprintf(writer, "PersistentVolume \"%s\" was updated.\n", persistentVolume.Name)
+	if err != nil {
+		return getApplyError("Service", service.Name, err)
+	}
+
+	k.usedKind.service = append(k.usedKind.service, service.Name)
+
+	fmt.Fprintf(writer, "Service \"%s\" was updated.\n", service.Name)
 
 	return nil
 }
@@ -256,12 +304,18 @@ func (k *kindService) upsertPersistentVolumeClaim(kubernetesNamespace string, pe
 	existingClaim, err := k.clientSet.CoreV1().PersistentVolumeClaims(kubernetesNamespace).Get(persistentVolumeClaim.Name, metaV1.GetOptions{})
 
 	if err != nil {
-		_, err := k.clientSet.CoreV1().PersistentVolumeClaims(kubernetesNamespace).Create(persistentVolumeClaim)
+		err = setLastAppliedAnnotation(persistentVolumeClaim)
 
 		if err != nil {
 			return err
 		}
 
+		_, err = k.clientSet.CoreV1().PersistentVolumeClaims(kubernetesNamespace).Create(persistentVolumeClaim)
+
+		if err != nil {
+			return getApplyError("PersistentVolumeClaim", persistentVolumeClaim.Name, err)
+		}
+
 		k.usedKind.persistentVolumeClaim = append(k.usedKind.persistentVolumeClaim, persistentVolumeClaim.Name)
 
 		fmt.Fprintf(writer, "PersistentVolumeClaim \"%s\" was generated.\n", persistentVolumeClaim.Name)
@@ -273,42 +327,21 @@ func (k *kindService) upsertPersistentVolumeClaim(kubernetesNamespace string, pe
 	// override with existing spec because spec is immutable
 	persistentVolumeClaim.Spec = existingClaim.Spec
 
-	_, err = k.clientSet.CoreV1().PersistentVolumeClaims(kubernetesNamespace).Update(persistentVolumeClaim)
+	patch, err := getApplyPatch(persistentVolumeClaim, existingClaim, coreV1.PersistentVolumeClaim{})
 
 	if err != nil {
 		return err
 	}
 
-	k.usedKind.persistentVolumeClaim = append(k.usedKind.persistentVolumeClaim, persistentVolumeClaim.Name)
-
-	fmt.Fprintf(writer, "PersistentVolumeClaim \"%s\" was updated.\n", persistentVolumeClaim.Name)
-
-	return nil
-}
-
-func (k *kindService) upsertIngress(kubernetesNamespace string, ingress *extensions.Ingress) error {
-
-	_, err := k.clientSet.ExtensionsV1beta1().Ingresses(kubernetesNamespace).Get(ingress.Name, metaV1.GetOptions{})
-
-	funcCall := k.clientSet.ExtensionsV1beta1().Ingresses(kubernetesNamespace).Update
-	message := "Ingress \"%s\" was updated.\n"
+	_, err = k.clientSet.CoreV1().PersistentVolumeClaims(kubernetesNamespace).Patch(persistentVolumeClaim.Name, types.StrategicMergePatchType, patch)
 
 	if err != nil {
-		funcCall = k.clientSet.ExtensionsV1beta1().Ingresses(kubernetesNamespace).Create
-		message = "Ingress \"%s\" was generated.\n"
+		return getApplyError("PersistentVolumeClaim", persistentVolumeClaim.Name, err)
 	}
 
-	_, err = funcCall(ingress)
-	if err != nil {
-		return err
-	}
-
-	k.usedKind.ingress = append(k.usedKind.ingress, ingress.Name)
-	fmt.Fprintf(writer, message, ingress.Name)
+	k.usedKind.persistentVolumeClaim = append(k.usedKind.persistentVolumeClaim, persistentVolumeClaim.Name)
 
-	if err != nil {
-		return err
-	}
+	fmt.Fprintf(writer, "PersistentVolumeClaim \"%s\" was updated.\n", persistentVolumeClaim.Name)
 
 	return nil
 }
@@ -334,17 +367,7 @@ func (k *kindService) setImageForContainer(annotations map[string]string, contai
 		switch annotations["imageUpdateStrategy"] {
 		case "latest-branching":
 
-			latestTag := loader.StagingEnvironment + "-" + namespaceWithoutPrefix + "-latest"
-
-			if namespaceWithoutPrefix == loader.StagingEnvironment {
-				latestTag = "staging-latest"
-			}
-
-			if namespaceWithoutPrefix == loader.ProductionEnvironment {
-				latestTag = "latest"
-			}
-
-			tag := getVersionForLatestTag(latestTag, images)
+			tag := getVersionForLatestTag(naming.LatestImageTag(namespaceWithoutPrefix), images)
 
 			if tag != "" {
 				containers[idx].Image += ":" + tag
@@ -2,81 +2,64 @@ package kind
 
 import (
 	"fmt"
-	"strings"
 
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// CleanupKind deletes the objects of the namespace which were not applied, objects of the kinds without special handling
+// are only deleted if they carry the ManagedLabel, except for the kinds which were pruned before the label existed.
+// Cluster scoped kinds like persistent volumes, cluster roles and
+// cluster role bindings can be shared by namespaces and are never pruned
 func (k *kindService) CleanupKind(kubernetesNamespace string) error {
 
-	err := k.cleanupSecret(kubernetesNamespace)
+	err := k.cleanupDeployment(kubernetesNamespace)
 
 	if err != nil {
 		return err
 	}
 
-	err = k.cleanupConfigMaps(kubernetesNamespace)
+	err = k.cleanupStatefulSets(kubernetesNamespace)
 
 	if err != nil {
 		return err
 	}
 
-	err = k.cleanupCronjobs(kubernetesNamespace)
+	err = k.cleanupJobs(kubernetesNamespace)
 
 	if err != nil {
 		return err
 	}
 
-	err = k.cleanupDeployment(kubernetesNamespace)
+	err = k.cleanupPersistentVolumeClaims(kubernetesNamespace)
 
 	if err != nil {
 		return err
 	}
 
-	err = k.cleanupIngresses(kubernetesNamespace)
+	err = k.cleanupServices(kubernetesNamespace)
 
 	if err != nil {
 		return err
 	}
 
-	err = k.cleanupPersistentVolumeClaims(kubernetesNamespace)
+	err = k.cleanupDefaultNetworkPolicy(kubernetesNamespace)
 
 	if err != nil {
 		return err
 	}
 
-	return k.cleanupServices(kubernetesNamespace)
+	return k.cleanupUnstructured(kubernetesNamespace)
 }
 
-func (k *kindService) cleanupSecret(kubernetesNamespace string) error {
-	list, err := k.clientSet.CoreV1().Secrets(kubernetesNamespace).List(metaV1.ListOptions{})
+// cleanupDefaultNetworkPolicy removes the default policy of the namespace as soon as the manifests define their own network policies
+func (k *kindService) cleanupDefaultNetworkPolicy(kubernetesNamespace string) error {
+	networkPolicies := k.usedKind.generic["networkpolicies"]
 
-	if err != nil {
-		return err
+	if len(networkPolicies) == 0 {
+		return nil
 	}
 
-	var names []string
-
-	for _, listEntry := range list.Items {
-		if strings.HasPrefix(listEntry.Name, "default-token-") {
-			continue
-		}
-		names = append(names, listEntry.Name)
-	}
-
-	for _, name := range difference(names, k.usedKind.secret) {
-		err = k.clientSet.CoreV1().Secrets(kubernetesNamespace).Delete(name, &metaV1.DeleteOptions{})
-		if err != nil {
-			return err
-		}
-		fmt.Fprintf(writer, "Secret \"%s\" was removed.\n", name)
-	}
-
-	return nil
-}
-
-func (k *kindService) cleanupConfigMaps(kubernetesNamespace string) error {
-	list, err := k.clientSet.CoreV1().ConfigMaps(kubernetesNamespace).List(metaV1.ListOptions{})
+	list, err := k.clientSet.NetworkingV1().NetworkPolicies(kubernetesNamespace).List(metaV1.ListOptions{LabelSelector: DefaultNetworkPolicyLabel + "=true"})
 
 	if err != nil {
 		return err
@@ -88,13 +71,13 @@ func (k *kindService) cleanupConfigMaps(kubernetesNamespace string) error {
 		names = append(names, listEntry.Name)
 	}
 
-	for _, name := range difference(names, k.usedKind.configMap) {
-		err = k.clientSet.CoreV1().ConfigMaps(kubernetesNamespace).Delete(name, &metaV1.DeleteOptions{})
+	for _, name := range difference(names, networkPolicies) {
+		err = k.clientSet.NetworkingV1().NetworkPolicies(kubernetesNamespace).Delete(name, &metaV1.DeleteOptions{})
 		if err != nil {
 			return err
 		}
 
-		fmt.Fprintf(writer, "ConfigMap \"%s\" was removed.\n", name)
+		fmt.Fprintf(writer, "NetworkPolicy \"%s\" was removed.\n", name)
 	}
 
 	return nil
@@ -150,8 +133,8 @@ func (k *kindService) cleanupDeployment(kubernetesNamespace string) error {
 	return nil
 }
 
-func (k *kindService) cleanupIngresses(kubernetesNamespace string) error {
-	list, err := k.clientSet.ExtensionsV1beta1().Ingresses(kubernetesNamespace).List(metaV1.ListOptions{})
+func (k *kindService) cleanupStatefulSets(kubernetesNamespace string) error {
+	list, err := k.clientSet.AppsV1().StatefulSets(kubernetesNamespace).List(metaV1.ListOptions{})
 
 	if err != nil {
 		return err
@@ -163,21 +146,20 @@ func (k *kindService) cleanupIngresses(kubernetesNamespace string) error {
 		names = append(names, listEntry.Name)
 	}
 
-	for _, name := range difference(names, k.usedKind.ingress) {
-		err = k.clientSet.ExtensionsV1beta1().Ingresses(kubernetesNamespace).Delete(name, &metaV1.DeleteOptions{})
+	for _, name := range difference(names, k.usedKind.statefulSet) {
+		err = k.clientSet.AppsV1().StatefulSets(kubernetesNamespace).Delete(name, &metaV1.DeleteOptions{})
 		if err != nil {
 			return err
 		}
 
-		fmt.Fprintf(writer, "Ingress \"%s\" was removed.\n", name)
+		fmt.Fprintf(writer, "StatefulSet \"%s\" was removed.\n", name)
 	}
 
 	return nil
 }
 
-func (k *kindService) cleanupCronjobs(kubernetesNamespace string) error {
-
-	list, err := k.clientSet.BatchV1beta1().CronJobs(kubernetesNamespace).List(metaV1.ListOptions{})
+func (k *kindService) cleanupJobs(kubernetesNamespace string) error {
+	list, err := k.clientSet.BatchV1().Jobs(kubernetesNamespace).List(metaV1.ListOptions{})
 
 	if err != nil {
 		return err
@@ -186,16 +168,20 @@ func (k *kindService) cleanupCronjobs(kubernetesNamespace string) error {
 	var names []string
 
 	for _, listEntry := range list.Items {
+		// jobs of a cron job are removed by the cron job itself
+		if metaV1.GetControllerOf(&listEntry) != nil {
+			continue
+		}
 		names = append(names, listEntry.Name)
 	}
 
-	for _, name := range difference(names, k.usedKind.cronJob) {
-		err = k.clientSet.BatchV1beta1().CronJobs(kubernetesNamespace).Delete(name, &metaV1.DeleteOptions{})
+	for _, name := range difference(names, k.usedKind.job) {
+		err = k.clientSet.BatchV1().Jobs(kubernetesNamespace).Delete(name, getDeleteOptionsWithDependents())
 		if err != nil {
 			return err
 		}
 
-		fmt.Fprintf(writer, "CronJob \"%s\" was removed.\n", name)
+		fmt.Fprintf(writer, "Job \"%s\" was removed.\n", name)
 	}
 
 	return nil
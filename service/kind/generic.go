@@ -0,0 +1,421 @@
+package kind
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	coreV1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	rbac "k8s.io/api/rbac/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// ManagedLabel marks the objects which are applied without a typed client, the cleanup only removes objects
+// of these kinds which have the label, e.g. the tokens of service accounts are never removed
+const ManagedLabel = "kube-helper/managed"
+
+// unlabeledCleanupResources are pruned like before the ManagedLabel existed, the objects of earlier releases
+// carry no label and would never be removed otherwise. The objects of the cluster itself are kept, see isKeptOnCleanup
+var unlabeledCleanupResources = map[schema.GroupResource]bool{
+	{Group: "", Resource: "configmaps"}:                            true,
+	{Group: "", Resource: "secrets"}:                               true,
+	{Group: "", Resource: "serviceaccounts"}:                       true,
+	{Group: "apps", Resource: "daemonsets"}:                        true,
+	{Group: "autoscaling", Resource: "horizontalpodautoscalers"}:   true,
+	{Group: "batch", Resource: "cronjobs"}:                         true,
+	{Group: "extensions", Resource: "daemonsets"}:                  true,
+	{Group: "extensions", Resource: "ingresses"}:                   true,
+	{Group: "extensions", Resource: "networkpolicies"}:             true,
+	{Group: "networking.k8s.io", Resource: "ingresses"}:            true,
+	{Group: "networking.k8s.io", Resource: "networkpolicies"}:      true,
+	{Group: "policy", Resource: "poddisruptionbudgets"}:            true,
+	{Group: "rbac.authorization.k8s.io", Resource: "rolebindings"}: true,
+	{Group: "rbac.authorization.k8s.io", Resource: "roles"}:        true,
+}
+
+// clusterScopedKinds are the kinds of the client scheme which are not namespaced, the scheme itself does not know the scope
+var clusterScopedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "ComponentStatus"}:                                            true,
+	{Group: "", Kind: "Namespace"}:                                                  true,
+	{Group: "", Kind: "Node"}:                                                       true,
+	{Group: "", Kind: "PersistentVolume"}:                                           true,
+	{Group: "admissionregistration.k8s.io", Kind: "InitializerConfiguration"}:       true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "authentication.k8s.io", Kind: "TokenReview"}:                           true,
+	{Group: "authorization.k8s.io", Kind: "SelfSubjectAccessReview"}:                true,
+	{Group: "authorization.k8s.io", Kind: "SelfSubjectRulesReview"}:                 true,
+	{Group: "authorization.k8s.io", Kind: "SubjectAccessReview"}:                    true,
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}:               true,
+	{Group: "extensions", Kind: "PodSecurityPolicy"}:                                true,
+	{Group: "policy", Kind: "PodSecurityPolicy"}:                                    true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                 true,
+	{Group: "storage.k8s.io", Kind: "VolumeAttachment"}:                             true,
+}
+
+var podDisruptionBudgetKind = schema.GroupKind{Group: policy.GroupName, Kind: "PodDisruptionBudget"}
+var roleBindingKind = schema.GroupKind{Group: rbac.GroupName, Kind: "RoleBinding"}
+var clusterRoleBindingKind = schema.GroupKind{Group: rbac.GroupName, Kind: "ClusterRoleBinding"}
+
+// getAPIResource finds the resource of a kind with the discovery of the server, kinds unknown to the server are not supported
+func (k *kindService) getAPIResource(groupVersionKind schema.GroupVersionKind) (*metaV1.APIResource, error) {
+	if resource, ok := k.apiResources[groupVersionKind]; ok {
+		return resource, nil
+	}
+
+	resourceList, err := k.clientSet.Discovery().ServerResourcesForGroupVersion(groupVersionKind.GroupVersion().String())
+
+	if apiErrors.IsNotFound(err) {
+		return nil, fmt.Errorf("kind %s is not supported", groupVersionKind.Kind)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range resourceList.APIResources {
+		if resource.Kind != groupVersionKind.Kind || strings.Contains(resource.Name, "/") {
+			continue
+		}
+
+		apiResource := resource
+		apiResource.Group = groupVersionKind.Group
+		apiResource.Version = groupVersionKind.Version
+		k.apiResources[groupVersionKind] = &apiResource
+
+		return &apiResource, nil
+	}
+
+	return nil, fmt.Errorf("kind %s is not supported", groupVersionKind.Kind)
+}
+
+func decodeUnstructured(data []byte) (*unstructured.Unstructured, error) {
+	content, err := yaml.ToJSON(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	object := new(unstructured.Unstructured)
+
+	err = object.UnmarshalJSON(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func convertToUnstructured(object runtime.Object, groupVersionKind *schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	unstructuredObject := &unstructured.Unstructured{Object: content}
+	unstructuredObject.SetGroupVersionKind(*groupVersionKind)
+
+	return unstructuredObject, nil
+}
+
+// renderUnstructured sets the namespace for namespaced kinds and marks the object as managed
+func (k *kindService) renderUnstructured(object *unstructured.Unstructured, kubernetesNamespace string) (runtime.Object, error) {
+	namespaced, err := k.isNamespaced(object.GroupVersionKind())
+
+	if err != nil {
+		return nil, err
+	}
+
+	object.SetNamespace("")
+
+	if namespaced {
+		object.SetNamespace(kubernetesNamespace)
+	}
+
+	labels := object.GetLabels()
+
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	labels[ManagedLabel] = "true"
+	object.SetLabels(labels)
+
+	return object, nil
+}
+
+// isNamespaced uses the discovery of the cluster, without a connection to the cluster the scope is taken
+// from the client scheme and unknown kinds are only treated as namespaced when they are allowed explicitly
+func (k *kindService) isNamespaced(groupVersionKind schema.GroupVersionKind) (bool, error) {
+	if k.clientSet != nil {
+		resource, err := k.getAPIResource(groupVersionKind)
+
+		if err != nil {
+			return false, err
+		}
+
+		return resource.Namespaced, nil
+	}
+
+	if !scheme.Scheme.Recognizes(groupVersionKind) && !k.allowUnknownKinds {
+		return false, fmt.Errorf("kind %s is not supported", groupVersionKind.Kind)
+	}
+
+	return !clusterScopedKinds[groupVersionKind.GroupKind()], nil
+}
+
+func (k *kindService) upsertUnstructured(object *unstructured.Unstructured) error {
+	groupVersionKind := object.GroupVersionKind()
+
+	resource, err := k.getAPIResource(groupVersionKind)
+
+	if err != nil {
+		return err
+	}
+
+	client, err := k.dynamicClientPool.ClientForGroupVersionKind(groupVersionKind)
+
+	if err != nil {
+		return err
+	}
+
+	resourceClient := client.Resource(resource, object.GetNamespace())
+
+	err = setSpecHashForImmutableSpec(object)
+
+	if err != nil {
+		return err
+	}
+
+	existingObject, err := resourceClient.Get(object.GetName(), metaV1.GetOptions{})
+
+	exists := err == nil
+	message := "%s \"%s\" was generated.\n"
+
+	if exists && isRecreateRequired(object, existingObject) {
+		err = resourceClient.Delete(object.GetName(), &metaV1.DeleteOptions{})
+
+		if err != nil {
+			return err
+		}
+
+		exists = false
+		message = "%s \"%s\" was recreated.\n"
+	}
+
+	if exists {
+		patchType, dataStruct := getPatchStrategy(groupVersionKind)
+
+		patch, err := getApplyPatch(object, existingObject, dataStruct)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = resourceClient.Patch(object.GetName(), patchType, patch)
+
+		if err != nil {
+			return getApplyError(groupVersionKind.Kind, object.GetName(), err)
+		}
+
+		message = "%s \"%s\" was updated.\n"
+	} else {
+		err = setLastAppliedAnnotation(object)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = resourceClient.Create(object)
+
+		if err != nil {
+			return getApplyError(groupVersionKind.Kind, object.GetName(), err)
+		}
+	}
+
+	k.usedKind.generic[resource.Name] = append(k.usedKind.generic[resource.Name], object.GetName())
+
+	fmt.Fprintf(writer, message, groupVersionKind.Kind, object.GetName())
+
+	return nil
+}
+
+// setSpecHashForImmutableSpec marks pod disruption budgets with the hash of their spec, the spec is immutable and
+// the budget is recreated as soon as the hash changed. The hash is taken from the typed spec like for jobs
+func setSpecHashForImmutableSpec(object *unstructured.Unstructured) error {
+	if object.GroupVersionKind().GroupKind() != podDisruptionBudgetKind {
+		return nil
+	}
+
+	disruptionBudget := new(policy.PodDisruptionBudget)
+
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, disruptionBudget)
+
+	if err != nil {
+		return err
+	}
+
+	specHash, err := getSpecHash(disruptionBudget.Spec)
+
+	if err != nil {
+		return err
+	}
+
+	annotations := object.GetAnnotations()
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	annotations[specHashAnnotation] = specHash
+	object.SetAnnotations(annotations)
+
+	return nil
+}
+
+// isRecreateRequired checks the immutable fields, the spec of a pod disruption budget and the role of a binding
+// can't be changed, such objects are deleted and created again
+func isRecreateRequired(object *unstructured.Unstructured, existingObject *unstructured.Unstructured) bool {
+	switch object.GroupVersionKind().GroupKind() {
+	case podDisruptionBudgetKind:
+		return object.GetAnnotations()[specHashAnnotation] != existingObject.GetAnnotations()[specHashAnnotation]
+	case roleBindingKind, clusterRoleBindingKind:
+		return !reflect.DeepEqual(object.Object["roleRef"], existingObject.Object["roleRef"])
+	}
+
+	return false
+}
+
+// getPatchStrategy uses a strategic merge patch for the kinds the client knows,
+// kinds without a go type have no patch strategy and get a json merge patch
+func getPatchStrategy(groupVersionKind schema.GroupVersionKind) (types.PatchType, interface{}) {
+	dataStruct, err := scheme.Scheme.New(groupVersionKind)
+
+	if err != nil {
+		return types.MergePatchType, nil
+	}
+
+	return types.StrategicMergePatchType, dataStruct
+}
+
+// cleanupUnstructured removes the managed objects of every namespaced kind the server knows which were not applied
+func (k *kindService) cleanupUnstructured(kubernetesNamespace string) error {
+	resourceLists, err := k.clientSet.Discovery().ServerResources()
+
+	// some api groups of the server can be unavailable, the objects of the available ones are still removed
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return err
+	}
+
+	supportsCleanup := discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}
+	cleanedUp := map[schema.GroupKind]bool{}
+
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+
+		if err != nil {
+			return err
+		}
+
+		for _, resource := range resourceList.APIResources {
+			groupKind := groupVersion.WithKind(resource.Kind).GroupKind()
+
+			if !resource.Namespaced || strings.Contains(resource.Name, "/") || cleanedUp[groupKind] || !supportsCleanup.Match(resourceList.GroupVersion, &resource) {
+				continue
+			}
+
+			cleanedUp[groupKind] = true
+
+			resource.Group = groupVersion.Group
+			resource.Version = groupVersion.Version
+
+			err = k.cleanupUnstructuredResource(kubernetesNamespace, groupVersion.WithKind(resource.Kind), resource)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (k *kindService) cleanupUnstructuredResource(kubernetesNamespace string, groupVersionKind schema.GroupVersionKind, resource metaV1.APIResource) error {
+	client, err := k.dynamicClientPool.ClientForGroupVersionKind(groupVersionKind)
+
+	if err != nil {
+		return err
+	}
+
+	resourceClient := client.Resource(&resource, kubernetesNamespace)
+
+	listOptions := metaV1.ListOptions{LabelSelector: ManagedLabel + "=true"}
+
+	if unlabeledCleanupResources[schema.GroupResource{Group: resource.Group, Resource: resource.Name}] {
+		listOptions = metaV1.ListOptions{}
+	}
+
+	list, err := resourceClient.List(listOptions)
+
+	if err != nil {
+		return err
+	}
+
+	unstructuredList, ok := list.(*unstructured.UnstructuredList)
+
+	if !ok {
+		return fmt.Errorf("unexpected list type %T for kind %s", list, groupVersionKind.Kind)
+	}
+
+	var names []string
+
+	for _, listEntry := range unstructuredList.Items {
+		if isKeptOnCleanup(resource.Name, listEntry) {
+			continue
+		}
+
+		names = append(names, listEntry.GetName())
+	}
+
+	for _, name := range difference(names, k.usedKind.generic[resource.Name]) {
+		err = resourceClient.Delete(name, &metaV1.DeleteOptions{})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(writer, "%s \"%s\" was removed.\n", groupVersionKind.Kind, name)
+	}
+
+	return nil
+}
+
+// isKeptOnCleanup protects the objects which are created by the cluster, e.g. the default service account and its token,
+// and the default network policy which has its own cleanup
+func isKeptOnCleanup(resource string, object unstructured.Unstructured) bool {
+	switch resource {
+	case "secrets":
+		secretType, _ := object.Object["type"].(string)
+
+		return strings.HasPrefix(object.GetName(), "default-token-") || secretType == string(coreV1.SecretTypeServiceAccountToken)
+	case "serviceaccounts":
+		return object.GetName() == "default"
+	case "networkpolicies":
+		return object.GetLabels()[DefaultNetworkPolicyLabel] == "true"
+	}
+
+	return false
+}
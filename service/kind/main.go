@@ -8,7 +8,10 @@ import (
 
 	"kube-helper/service/image"
 
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 )
@@ -17,37 +20,54 @@ var writer io.Writer = os.Stdout
 
 type KindInterface interface {
 	ApplyKind(kubernetesNamespace string, fileLines []string, namespaceWithoutPrefix string) error
+	RenderKind(kubernetesNamespace string, fileLines []string, namespaceWithoutPrefix string) ([]runtime.Object, error)
 	CleanupKind(kubernetesNamespace string) error
 }
 
 type usedKind struct {
-	secret                []string
-	cronJob               []string
 	deployment            []string
+	statefulSet           []string
+	job                   []string
 	service               []string
-	ingress               []string
-	configMap             []string
-	persistentVolume      []string
 	persistentVolumeClaim []string
+	// generic holds the names of the objects applied with the dynamic client by their resource, e.g. daemonsets,
+	// some servers serve the same objects under more than one group and the cleanup of every group has to see them
+	generic map[string][]string
 }
 
 type kindService struct {
-	decoder       runtime.Decoder
-	clientSet     kubernetes.Interface
-	imagesService image.ImagesInterface
-	config        loader.Config
-	usedKind      usedKind
+	decoder           runtime.Decoder
+	clientSet         kubernetes.Interface
+	dynamicClientPool dynamic.ClientPool
+	imagesService     image.ImagesInterface
+	config            loader.Config
+	usedKind          usedKind
+	apiResources      map[schema.GroupVersionKind]*metaV1.APIResource
+	allowUnknownKinds bool
 }
 
 // NewKind is the constructor method and returns a service which implements the KindInterface
-// the service is used to apply different kubernetes kinds and also do a cleanup depending on the applied ones
-func NewKind(client kubernetes.Interface, imagesService image.ImagesInterface, config loader.Config) KindInterface {
+// the service is used to apply different kubernetes kinds and also do a cleanup depending on the applied ones,
+// kinds without a typed client are applied with the dynamic client pool
+func NewKind(client kubernetes.Interface, dynamicClientPool dynamic.ClientPool, imagesService image.ImagesInterface, config loader.Config) KindInterface {
 	k := new(kindService)
 	k.clientSet = client
+	k.dynamicClientPool = dynamicClientPool
 	k.imagesService = imagesService
 	k.config = config
-	k.usedKind = usedKind{}
+	k.usedKind = usedKind{generic: map[string][]string{}}
+	k.apiResources = map[schema.GroupVersionKind]*metaV1.APIResource{}
 	k.decoder = scheme.Codecs.UniversalDeserializer()
 
 	return k
 }
+
+// NewOfflineKind returns a service which only renders the manifests, without a connection to the cluster
+// the scope of the kinds is taken from the client scheme and kinds unknown to the scheme are only rendered,
+// as namespaced objects, when allowUnknownKinds is set
+func NewOfflineKind(imagesService image.ImagesInterface, config loader.Config, allowUnknownKinds bool) KindInterface {
+	k := NewKind(nil, nil, imagesService, config).(*kindService)
+	k.allowUnknownKinds = allowUnknownKinds
+
+	return k
+}
@@ -2,35 +2,47 @@ package kind
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"kube-helper/loader"
 	"kube-helper/mocks"
 	"kube-helper/model"
+	"strings"
 	"testing"
 
 	testingKube "kube-helper/testing"
 
 	"github.com/stretchr/testify/assert"
 	apps "k8s.io/api/apps/v1"
+	autoscalingV1 "k8s.io/api/autoscaling/v1"
+	autoscalingV2 "k8s.io/api/autoscaling/v2beta1"
+	batchV1 "k8s.io/api/batch/v1"
 	batch "k8s.io/api/batch/v1beta1"
 	coreV1 "k8s.io/api/core/v1"
 	extensions "k8s.io/api/extensions/v1beta1"
+	networking "k8s.io/api/networking/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	rbac "k8s.io/api/rbac/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	testingK8s "k8s.io/client-go/testing"
 )
 
 var listErrorTests = []struct {
 	resource string
 }{
-	{"secrets"},
-	{"configmaps"},
 	{"services"},
 	{"deployments"},
-	{"ingresses"},
-	{"cronjobs"},
+	{"statefulsets"},
+	{"jobs"},
 	{"persistentvolumeclaims"},
 }
 
@@ -51,13 +63,11 @@ var deleteErrorTests = []struct {
 	resource string
 	list     runtime.Object
 }{
-	{"secrets", &coreV1.SecretList{Items: []coreV1.Secret{{ObjectMeta: meta.ObjectMeta{Name: "default-token-fff"}}, {ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}},
-	{"configmaps", &coreV1.ConfigMapList{Items: []coreV1.ConfigMap{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}},
 	{"services", &coreV1.ServiceList{Items: []coreV1.Service{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}},
 	{"persistentvolumeclaims", &coreV1.PersistentVolumeClaimList{Items: []coreV1.PersistentVolumeClaim{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}},
 	{"deployments", &apps.DeploymentList{Items: []apps.Deployment{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}},
-	{"ingresses", &extensions.IngressList{Items: []extensions.Ingress{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}},
-	{"cronjobs", &batch.CronJobList{Items: []batch.CronJob{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}},
+	{"statefulsets", &apps.StatefulSetList{Items: []apps.StatefulSet{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}},
+	{"jobs", &batchV1.JobList{Items: []batchV1.Job{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}},
 }
 
 func TestKindService_CleanupKindWithErrorOnDeleteKind(t *testing.T) {
@@ -78,15 +88,15 @@ var deleteTests = []struct {
 	list     runtime.Object
 	out      string
 }{
-	{"secrets", &coreV1.SecretList{Items: []coreV1.Secret{{ObjectMeta: meta.ObjectMeta{Name: "default-token-fff"}}, {ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}, "Secret \"dummy\" was removed.\n"},
-	{"configmaps", &coreV1.ConfigMapList{Items: []coreV1.ConfigMap{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}, "ConfigMap \"dummy\" was removed.\n"},
 	{"services", &coreV1.ServiceList{Items: []coreV1.Service{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}, "Service \"dummy\" was removed.\n"},
 	{"persistentvolumeclaims", &coreV1.PersistentVolumeClaimList{Items: []coreV1.PersistentVolumeClaim{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}, "PersistentVolumeClaim \"dummy\" was removed.\n"},
 	{"deployments", &apps.DeploymentList{Items: []apps.Deployment{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}, "Deployment \"dummy\" was removed.\n"},
-	{"ingresses", &extensions.IngressList{Items: []extensions.Ingress{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}, "Ingress \"dummy\" was removed.\n"},
-	{"cronjobs", &batch.CronJobList{Items: []batch.CronJob{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}, "CronJob \"dummy\" was removed.\n"},
+	{"statefulsets", &apps.StatefulSetList{Items: []apps.StatefulSet{{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}, "StatefulSet \"dummy\" was removed.\n"},
+	{"jobs", &batchV1.JobList{Items: []batchV1.Job{{ObjectMeta: meta.ObjectMeta{Name: "cron-1", OwnerReferences: []meta.OwnerReference{{Kind: "CronJob", Name: "cron", Controller: &isController}}}}, {ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}, "Job \"dummy\" was removed.\n"},
 }
 
+var isController = true
+
 func TestKindService_CleanupKind(t *testing.T) {
 	for _, entry := range deleteTests {
 		config := loader.Config{}
@@ -96,17 +106,194 @@ func TestKindService_CleanupKind(t *testing.T) {
 		fakeClientSet.PrependReactor("list", entry.resource, testingKube.GetObjectReturnFunc(entry.list))
 		fakeClientSet.PrependReactor("delete", entry.resource, testingKube.NilReturnFunc)
 
-		kindService.usedKind.secret = append(kindService.usedKind.secret, "foobarUsed")
+		kindService.usedKind.service = append(kindService.usedKind.service, "foobarUsed")
+
+		output := captureOutput(func() {
+			assert.NoError(t, kindService.CleanupKind("foobar"), fmt.Sprintf("Test failed for resource %s", entry.resource))
+		})
+
+		assert.Equal(t, entry.out, output, fmt.Sprintf("Test failed for resource %s", entry.resource))
+		assert.Len(t, fakeClientSet.Actions(), 6)
+	}
+}
+
+func TestKindService_CleanupKindKeepsDefaultNetworkPolicyWithoutOwnPolicies(t *testing.T) {
+	kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+	fakeClientSet.PrependReactor("list", "networkpolicies", testingKube.ErrorReturnFunc)
+
+	assert.NoError(t, kindService.CleanupKind("foobar"))
+}
+
+func TestKindService_CleanupKindRemovesDefaultNetworkPolicyForOwnPolicies(t *testing.T) {
+	kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+	list := &networking.NetworkPolicyList{Items: []networking.NetworkPolicy{*NewDefaultNetworkPolicy("foobar", loader.NetworkPolicy{}), {ObjectMeta: meta.ObjectMeta{Name: "dummy"}}}}
+
+	fakeClientSet.PrependReactor("list", "networkpolicies", testingKube.GetObjectReturnFunc(list))
+	fakeClientSet.PrependReactor("delete", "networkpolicies", testingKube.NilReturnFunc)
+
+	kindService.usedKind.generic["networkpolicies"] = []string{"dummy"}
+
+	output := captureOutput(func() {
+		assert.NoError(t, kindService.CleanupKind("foobar"))
+	})
+
+	assert.Equal(t, "NetworkPolicy \"default-isolation\" was removed.\n", output)
+}
+
+func TestKindService_CleanupKindWithErrorForDefaultNetworkPolicy(t *testing.T) {
+	for _, verb := range []string{"list", "delete"} {
+		kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+		list := &networking.NetworkPolicyList{Items: []networking.NetworkPolicy{*NewDefaultNetworkPolicy("foobar", loader.NetworkPolicy{})}}
+
+		fakeClientSet.PrependReactor("list", "networkpolicies", testingKube.GetObjectReturnFunc(list))
+		fakeClientSet.PrependReactor(verb, "networkpolicies", testingKube.ErrorReturnFunc)
+
+		kindService.usedKind.generic["networkpolicies"] = []string{"dummy"}
+
+		assert.EqualError(t, kindService.CleanupKind("foobar"), "explode", fmt.Sprintf("Test failed for verb %s", verb))
+	}
+}
+
+var managedDeleteTests = []struct {
+	resource string
+	kind     string
+	out      string
+}{
+	{"secrets", "Secret", "Secret \"dummy\" was removed.\nSecret \"foreign\" was removed.\n"},
+	{"configmaps", "ConfigMap", "ConfigMap \"dummy\" was removed.\nConfigMap \"foreign\" was removed.\n"},
+	{"serviceaccounts", "ServiceAccount", "ServiceAccount \"dummy\" was removed.\nServiceAccount \"foreign\" was removed.\n"},
+	{"daemonsets", "DaemonSet", "DaemonSet \"dummy\" was removed.\nDaemonSet \"foreign\" was removed.\n"},
+	{"cronjobs", "CronJob", "CronJob \"dummy\" was removed.\nCronJob \"foreign\" was removed.\n"},
+	{"ingresses", "Ingress", "Ingress \"dummy\" was removed.\nIngress \"foreign\" was removed.\n"},
+	{"horizontalpodautoscalers", "HorizontalPodAutoscaler", "HorizontalPodAutoscaler \"dummy\" was removed.\nHorizontalPodAutoscaler \"foreign\" was removed.\n"},
+	{"poddisruptionbudgets", "PodDisruptionBudget", "PodDisruptionBudget \"dummy\" was removed.\nPodDisruptionBudget \"foreign\" was removed.\n"},
+	{"networkpolicies", "NetworkPolicy", "NetworkPolicy \"dummy\" was removed.\nNetworkPolicy \"foreign\" was removed.\n"},
+	{"roles", "Role", "Role \"dummy\" was removed.\nRole \"foreign\" was removed.\n"},
+	{"rolebindings", "RoleBinding", "RoleBinding \"dummy\" was removed.\nRoleBinding \"foreign\" was removed.\n"},
+	{"certificates", "Certificate", "Certificate \"dummy\" was removed.\n"},
+}
+
+func TestKindService_CleanupKindRemovesManagedObjects(t *testing.T) {
+	for _, entry := range managedDeleteTests {
+		kindService, _, _ := getKindService(loader.Config{})
+
+		dynamicClientPool := kindService.dynamicClientPool.(*dynamicFake.FakeClientPool)
+
+		list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+			{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "dummy", "labels": map[string]interface{}{ManagedLabel: "true"}}}},
+			{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "used", "labels": map[string]interface{}{ManagedLabel: "true"}}}},
+			{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "foreign"}}},
+		}}
+
+		dynamicClientPool.PrependReactor("list", entry.resource, testingKube.GetObjectReturnFunc(list))
+		dynamicClientPool.PrependReactor("delete", entry.resource, testingKube.NilReturnFunc)
+
+		kindService.usedKind.generic[entry.resource] = []string{"used"}
 
 		output := captureOutput(func() {
 			assert.NoError(t, kindService.CleanupKind("foobar"), fmt.Sprintf("Test failed for resource %s", entry.resource))
 		})
 
 		assert.Equal(t, entry.out, output, fmt.Sprintf("Test failed for resource %s", entry.resource))
-		assert.Len(t, fakeClientSet.Actions(), 8)
 	}
 }
 
+func TestKindService_CleanupKindKeepsObjectsOfTheCluster(t *testing.T) {
+	for _, entry := range []struct {
+		resource string
+		object   map[string]interface{}
+	}{
+		{"secrets", map[string]interface{}{"metadata": map[string]interface{}{"name": "default-token-abc"}}},
+		{"secrets", map[string]interface{}{"metadata": map[string]interface{}{"name": "dummy-token-abc"}, "type": "kubernetes.io/service-account-token"}},
+		{"serviceaccounts", map[string]interface{}{"metadata": map[string]interface{}{"name": "default"}}},
+		{"networkpolicies", map[string]interface{}{"metadata": map[string]interface{}{"name": "default", "labels": map[string]interface{}{DefaultNetworkPolicyLabel: "true"}}}},
+	} {
+		kindService, _, _ := getKindService(loader.Config{})
+
+		dynamicClientPool := kindService.dynamicClientPool.(*dynamicFake.FakeClientPool)
+
+		list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{{Object: entry.object}}}
+
+		dynamicClientPool.PrependReactor("list", entry.resource, testingKube.GetObjectReturnFunc(list))
+		dynamicClientPool.PrependReactor("delete", entry.resource, testingKube.ErrorReturnFunc)
+
+		output := captureOutput(func() {
+			assert.NoError(t, kindService.CleanupKind("foobar"), fmt.Sprintf("Test failed for resource %s", entry.resource))
+		})
+
+		assert.Empty(t, output, fmt.Sprintf("Test failed for resource %s", entry.resource))
+	}
+}
+
+func TestKindService_CleanupKindKeepsObjectsOfAliasGroups(t *testing.T) {
+	kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+	addAliasGroups(fakeClientSet)
+
+	dynamicClientPool := kindService.dynamicClientPool.(*dynamicFake.FakeClientPool)
+
+	output := captureOutput(func() {
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{daemonSet}, "foobar"))
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{networkPolicy}, "foobar"))
+	})
+
+	assert.Equal(t, "DaemonSet \"dummy\" was updated.\nNetworkPolicy \"dummy\" was updated.\n", output)
+
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "dummy", "labels": map[string]interface{}{ManagedLabel: "true"}}}},
+	}}
+
+	for _, resource := range []string{"daemonsets", "networkpolicies"} {
+		dynamicClientPool.PrependReactor("list", resource, testingKube.GetObjectReturnFunc(list))
+		dynamicClientPool.PrependReactor("delete", resource, testingKube.ErrorReturnFunc)
+	}
+
+	output = captureOutput(func() {
+		assert.NoError(t, kindService.CleanupKind("foobar"))
+	})
+
+	assert.Empty(t, output)
+}
+
+func TestKindService_CleanupKindSkipsClusterScopedKinds(t *testing.T) {
+	kindService, _, _ := getKindService(loader.Config{})
+
+	dynamicClientPool := kindService.dynamicClientPool.(*dynamicFake.FakeClientPool)
+
+	for _, resource := range []string{"persistentvolumes", "clusterroles", "clusterrolebindings"} {
+		dynamicClientPool.PrependReactor("*", resource, testingKube.ErrorReturnFunc)
+	}
+
+	assert.NoError(t, kindService.CleanupKind("foobar"))
+}
+
+func TestKindService_CleanupKindWithErrorForManagedObjects(t *testing.T) {
+	for _, verb := range []string{"list", "delete"} {
+		kindService, _, _ := getKindService(loader.Config{})
+
+		dynamicClientPool := kindService.dynamicClientPool.(*dynamicFake.FakeClientPool)
+
+		list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+			{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "dummy", "labels": map[string]interface{}{ManagedLabel: "true"}}}},
+		}}
+
+		dynamicClientPool.PrependReactor("list", "certificates", testingKube.GetObjectReturnFunc(list))
+		dynamicClientPool.PrependReactor(verb, "certificates", testingKube.ErrorReturnFunc)
+
+		assert.EqualError(t, kindService.CleanupKind("foobar"), "explode", fmt.Sprintf("Test failed for verb %s", verb))
+	}
+}
+
+var certificate = `kind: Certificate
+apiVersion: certmanager.k8s.io/v1alpha1
+metadata:
+  name: dummy
+spec:
+  secretName: dummy-tls`
+
 var secret = `kind: Secret
 apiVersion: v1
 type: Opaque
@@ -140,6 +327,45 @@ apiVersion: v1
 metadata:
   name: dummy`
 
+var serviceAccount = `kind: ServiceAccount
+apiVersion: v1
+metadata:
+  name: dummy`
+
+var role = `kind: Role
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: dummy`
+
+var roleBinding = `kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: dummy
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: dummy
+subjects:
+- kind: ServiceAccount
+  name: dummy`
+
+var clusterRole = `kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: dummy`
+
+var clusterRoleBinding = `kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: dummy
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: dummy
+subjects:
+- kind: ServiceAccount
+  name: dummy`
+
 var deployment = `kind: Deployment
 apiVersion: apps/v1
 metadata:
@@ -159,11 +385,99 @@ spec:
       - name: deploy
         image: eu.gcr.io/foobar/app`
 
+var statefulSet = `kind: StatefulSet
+apiVersion: apps/v1
+metadata:
+  name: dummy`
+
+var statefulSetWithAnnotation = `kind: StatefulSet
+apiVersion: apps/v1
+metadata:
+  name: dummy
+  annotations:
+    imageUpdateStrategy: "latest-branching"
+spec:
+  serviceName: dummy
+  template:
+    spec:
+      containers:
+      - name: database
+        image: eu.gcr.io/foobar/app`
+
+var daemonSet = `kind: DaemonSet
+apiVersion: apps/v1
+metadata:
+  name: dummy`
+
+var daemonSetWithAnnotation = `kind: DaemonSet
+apiVersion: apps/v1
+metadata:
+  name: dummy
+  annotations:
+    imageUpdateStrategy: "latest-branching"
+spec:
+  template:
+    spec:
+      containers:
+      - name: logs
+        image: eu.gcr.io/foobar/app`
+
+var job = `kind: Job
+apiVersion: batch/v1
+metadata:
+  name: dummy
+spec:
+  template:
+    spec:
+      containers:
+      - name: migration
+        image: busy`
+
 var ingress = `kind: Ingress
 apiVersion: extensions/v1beta1
 metadata:
   name: dummy`
 
+var horizontalPodAutoscaler = `kind: HorizontalPodAutoscaler
+apiVersion: autoscaling/v1
+metadata:
+  name: dummy
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: dummy
+  minReplicas: 2
+  maxReplicas: 5`
+
+var horizontalPodAutoscalerV2 = `kind: HorizontalPodAutoscaler
+apiVersion: autoscaling/v2beta1
+metadata:
+  name: dummy
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: dummy
+  maxReplicas: 5`
+
+var podDisruptionBudget = `kind: PodDisruptionBudget
+apiVersion: policy/v1beta1
+metadata:
+  name: dummy
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: dummy`
+
+var networkPolicy = `kind: NetworkPolicy
+apiVersion: networking.k8s.io/v1
+metadata:
+  name: dummy
+spec:
+  podSelector: {}`
+
 var cronjob = `kind: CronJob
 apiVersion: batch/v1beta1
 metadata:
@@ -197,8 +511,20 @@ var insertTests = []struct {
 	{"services", service, "Service \"dummy\" was generated.\n"},
 	{"persistentvolumeclaims", persistentVolumeClaim, "PersistentVolumeClaim \"dummy\" was generated.\n"},
 	{"persistentvolumes", persistentVolume, "PersistentVolume \"dummy\" was generated.\n"},
+	{"serviceaccounts", serviceAccount, "ServiceAccount \"dummy\" was generated.\n"},
+	{"roles", role, "Role \"dummy\" was generated.\n"},
+	{"rolebindings", roleBinding, "RoleBinding \"dummy\" was generated.\n"},
+	{"clusterroles", clusterRole, "ClusterRole \"dummy\" was generated.\n"},
+	{"clusterrolebindings", clusterRoleBinding, "ClusterRoleBinding \"dummy\" was generated.\n"},
 	{"deployments", deployment, "Deployment \"dummy\" was generated.\n"},
+	{"statefulsets", statefulSet, "StatefulSet \"dummy\" was generated.\n"},
+	{"daemonsets", daemonSet, "DaemonSet \"dummy\" was generated.\n"},
+	{"jobs", job, "Job \"dummy\" was generated.\n"},
 	{"ingresses", ingress, "Ingress \"dummy\" was generated.\n"},
+	{"horizontalpodautoscalers", horizontalPodAutoscaler, "HorizontalPodAutoscaler \"dummy\" was generated.\n"},
+	{"horizontalpodautoscalers", horizontalPodAutoscalerV2, "HorizontalPodAutoscaler \"dummy\" was generated.\n"},
+	{"poddisruptionbudgets", podDisruptionBudget, "PodDisruptionBudget \"dummy\" was generated.\n"},
+	{"networkpolicies", networkPolicy, "NetworkPolicy \"dummy\" was generated.\n"},
 	{"cronjobs", cronjob, "CronJob \"dummy\" was generated.\n"},
 }
 
@@ -208,15 +534,25 @@ var upsertTests = []struct {
 	out      string
 	object   runtime.Object
 }{
-	{"secrets", secret, "Secret \"dummy\" was updated.\n", nil},
-	{"configmaps", configMap, "ConfigMap \"dummy\" was updated.\n", nil},
+	{"secrets", secret, "Secret \"dummy\" was updated.\n", &coreV1.Secret{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"configmaps", configMap, "ConfigMap \"dummy\" was updated.\n", &coreV1.ConfigMap{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
 	{"services", service, "Service \"dummy\" was updated.\n", &coreV1.Service{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
 	{"services", serviceWithAnnotation, "Service \"dummy\" was updated.\n", &coreV1.Service{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
 	{"persistentvolumeclaims", persistentVolumeClaim, "PersistentVolumeClaim \"dummy\" was updated.\n", &coreV1.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
-	{"persistentvolumes", persistentVolume, "PersistentVolume \"dummy\" was updated.\n", nil},
-	{"deployments", deployment, "Deployment \"dummy\" was updated.\n", nil},
-	{"ingresses", ingress, "Ingress \"dummy\" was updated.\n", nil},
-	{"cronjobs", cronjob, "CronJob \"dummy\" was updated.\n", nil},
+	{"persistentvolumes", persistentVolume, "PersistentVolume \"dummy\" was updated.\n", &coreV1.PersistentVolume{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"serviceaccounts", serviceAccount, "ServiceAccount \"dummy\" was updated.\n", &coreV1.ServiceAccount{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"roles", role, "Role \"dummy\" was updated.\n", &rbac.Role{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"rolebindings", roleBinding, "RoleBinding \"dummy\" was updated.\n", &rbac.RoleBinding{ObjectMeta: meta.ObjectMeta{Name: "dummy"}, RoleRef: rbac.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "dummy"}}},
+	{"clusterroles", clusterRole, "ClusterRole \"dummy\" was updated.\n", &rbac.ClusterRole{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"clusterrolebindings", clusterRoleBinding, "ClusterRoleBinding \"dummy\" was updated.\n", &rbac.ClusterRoleBinding{ObjectMeta: meta.ObjectMeta{Name: "dummy"}, RoleRef: rbac.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "dummy"}}},
+	{"deployments", deployment, "Deployment \"dummy\" was updated.\n", &apps.Deployment{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"statefulsets", statefulSet, "StatefulSet \"dummy\" was updated.\n", &apps.StatefulSet{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"daemonsets", daemonSet, "DaemonSet \"dummy\" was updated.\n", &apps.DaemonSet{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"ingresses", ingress, "Ingress \"dummy\" was updated.\n", &extensions.Ingress{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"horizontalpodautoscalers", horizontalPodAutoscaler, "HorizontalPodAutoscaler \"dummy\" was updated.\n", &autoscalingV1.HorizontalPodAutoscaler{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"horizontalpodautoscalers", horizontalPodAutoscalerV2, "HorizontalPodAutoscaler \"dummy\" was updated.\n", &autoscalingV2.HorizontalPodAutoscaler{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"networkpolicies", networkPolicy, "NetworkPolicy \"dummy\" was updated.\n", &networking.NetworkPolicy{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"cronjobs", cronjob, "CronJob \"dummy\" was updated.\n", &batch.CronJob{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
 }
 
 var setImageTests = []struct {
@@ -225,8 +561,10 @@ var setImageTests = []struct {
 	out      string
 	object   runtime.Object
 }{
-	{"cronjobs", cronjobWithAnnotation, "CronJob \"dummy\" was updated.\n", nil},
-	{"deployments", deploymentWithAnnotation, "Deployment \"dummy\" was updated.\n", nil},
+	{"cronjobs", cronjobWithAnnotation, "CronJob \"dummy\" was updated.\n", &batch.CronJob{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"deployments", deploymentWithAnnotation, "Deployment \"dummy\" was updated.\n", &apps.Deployment{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"statefulsets", statefulSetWithAnnotation, "StatefulSet \"dummy\" was updated.\n", &apps.StatefulSet{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
+	{"daemonsets", daemonSetWithAnnotation, "DaemonSet \"dummy\" was updated.\n", &apps.DaemonSet{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}},
 }
 
 func TestKindService_ApplyKindShouldFailWithErrorDuringDecode(t *testing.T) {
@@ -237,7 +575,7 @@ apiVersion: v1
 metadata:
   name: dummy`
 
-	assert.EqualError(t, kindService.ApplyKind("foobar", []string{kind}, "foobar"), "no kind \"Pod2\" is registered for version \"v1\"")
+	assert.EqualError(t, kindService.ApplyKind("foobar", []string{kind}, "foobar"), "kind Pod2 is not supported")
 }
 
 func TestKindService_ApplyKindShouldFailWithInvalidKind(t *testing.T) {
@@ -251,15 +589,88 @@ metadata:
 	assert.EqualError(t, kindService.ApplyKind("foobar", []string{kind}, "foobar"), "kind Pod is not supported")
 }
 
+func TestKindService_ApplyKindCustomResourceInsert(t *testing.T) {
+	kindService, _, _ := getKindService(loader.Config{})
+
+	dynamicClientPool := kindService.dynamicClientPool.(*dynamicFake.FakeClientPool)
+	dynamicClientPool.PrependReactor("get", "certificates", testingKube.ErrorReturnFunc)
+	dynamicClientPool.PrependReactor("create", "certificates", testingKube.NilReturnFunc)
+
+	output := captureOutput(func() {
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{certificate}, "foobar"))
+	})
+
+	assert.Equal(t, "Certificate \"dummy\" was generated.\n", output)
+	assert.Len(t, dynamicClientPool.Actions(), 2)
+
+	created := dynamicClientPool.Actions()[1].(testingK8s.CreateAction).GetObject().(*unstructured.Unstructured)
+
+	assert.Equal(t, "foobar", created.GetNamespace())
+	assert.Equal(t, "true", created.GetLabels()[ManagedLabel])
+	assert.NotEmpty(t, created.GetAnnotations()[LastAppliedAnnotation])
+	assert.Equal(t, []string{"dummy"}, kindService.usedKind.generic["certificates"])
+}
+
+func TestKindService_ApplyKindCustomResourceUpdate(t *testing.T) {
+	kindService, _, _ := getKindService(loader.Config{})
+
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":        "dummy",
+			"annotations": map[string]interface{}{LastAppliedAnnotation: `{"spec":{"commonName":"old","secretName":"dummy-tls"}}`},
+		},
+		"spec": map[string]interface{}{"commonName": "old", "secretName": "dummy-tls", "acme": "set by the controller"},
+	}}
+
+	dynamicClientPool := kindService.dynamicClientPool.(*dynamicFake.FakeClientPool)
+	dynamicClientPool.PrependReactor("get", "certificates", testingKube.GetObjectReturnFunc(existing))
+	dynamicClientPool.PrependReactor("patch", "certificates", testingKube.NilReturnFunc)
+
+	output := captureOutput(func() {
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{certificate}, "foobar"))
+	})
+
+	assert.Equal(t, "Certificate \"dummy\" was updated.\n", output)
+
+	patch := string(dynamicClientPool.Actions()[1].(testingK8s.PatchAction).GetPatch())
+
+	assert.Contains(t, patch, `"commonName":null`)
+	assert.NotContains(t, patch, "acme")
+}
+
+func TestGetPatchStrategy(t *testing.T) {
+	patchType, dataStruct := getPatchStrategy(schema.GroupVersionKind{Version: "v1", Kind: "Secret"})
+
+	assert.Equal(t, types.StrategicMergePatchType, patchType)
+	assert.IsType(t, &coreV1.Secret{}, dataStruct)
+
+	patchType, dataStruct = getPatchStrategy(schema.GroupVersionKind{Group: "certmanager.k8s.io", Version: "v1alpha1", Kind: "Certificate"})
+
+	assert.Equal(t, types.MergePatchType, patchType)
+	assert.Nil(t, dataStruct)
+}
+
+func TestKindService_ApplyKindCustomResourceWithError(t *testing.T) {
+	kindService, _, _ := getKindService(loader.Config{})
+
+	dynamicClientPool := kindService.dynamicClientPool.(*dynamicFake.FakeClientPool)
+	dynamicClientPool.PrependReactor("get", "certificates", testingKube.ErrorReturnFunc)
+	dynamicClientPool.PrependReactor("create", "certificates", testingKube.ErrorReturnFunc)
+
+	assert.EqualError(t, kindService.ApplyKind("foobar", []string{certificate}, "foobar"), "Certificate \"dummy\" could not be applied: explode")
+}
+
 func TestKindService_ApplyKindInsertWithError(t *testing.T) {
 	for _, entry := range insertTests {
 		config := loader.Config{}
 
-		kindService, _, fakeClientSet := getKindServiceInterface(config)
-		fakeClientSet.PrependReactor("get", entry.resource, testingKube.ErrorReturnFunc)
-		fakeClientSet.PrependReactor("create", entry.resource, testingKube.ErrorReturnFunc)
+		kindService, _, fakeClientSet := getKindService(config)
+		prependReactor(kindService, fakeClientSet, "get", entry.resource, testingKube.ErrorReturnFunc)
+		prependReactor(kindService, fakeClientSet, "create", entry.resource, testingKube.ErrorReturnFunc)
+
+		expectedError := strings.TrimSuffix(entry.out, " was generated.\n") + " could not be applied: explode"
 
-		assert.EqualError(t, kindService.ApplyKind("foobar", []string{entry.kind}, "foobar"), "explode", fmt.Sprintf("Test failed for resource %s", entry.resource))
+		assert.EqualError(t, kindService.ApplyKind("foobar", []string{entry.kind}, "foobar"), expectedError, fmt.Sprintf("Test failed for resource %s", entry.resource))
 	}
 }
 
@@ -267,9 +678,9 @@ func TestKindService_ApplyKindInsert(t *testing.T) {
 	for _, entry := range insertTests {
 		config := loader.Config{}
 
-		kindService, _, fakeClientSet := getKindServiceInterface(config)
-		fakeClientSet.PrependReactor("get", entry.resource, testingKube.ErrorReturnFunc)
-		fakeClientSet.PrependReactor("create", entry.resource, testingKube.NilReturnFunc)
+		kindService, _, fakeClientSet := getKindService(config)
+		prependReactor(kindService, fakeClientSet, "get", entry.resource, testingKube.ErrorReturnFunc)
+		prependReactor(kindService, fakeClientSet, "create", entry.resource, testingKube.NilReturnFunc)
 
 		output := captureOutput(func() {
 			assert.NoError(t, kindService.ApplyKind("foobar", []string{entry.kind}, "foobar"), fmt.Sprintf("Test failed for resource %s", entry.resource))
@@ -279,15 +690,36 @@ func TestKindService_ApplyKindInsert(t *testing.T) {
 	}
 }
 
+func TestKindService_ApplyKindWithList(t *testing.T) {
+	kindService, _, fakeClientSet := getKindService(loader.Config{})
+	prependReactor(kindService, fakeClientSet, "get", "secrets", testingKube.ErrorReturnFunc)
+	prependReactor(kindService, fakeClientSet, "create", "secrets", testingKube.NilReturnFunc)
+	prependReactor(kindService, fakeClientSet, "get", "configmaps", testingKube.ErrorReturnFunc)
+	prependReactor(kindService, fakeClientSet, "create", "configmaps", testingKube.NilReturnFunc)
+
+	var list = `{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "dummy"}},
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "dummy"}}
+]}`
+
+	output := captureOutput(func() {
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{list}, "foobar"))
+	})
+
+	assert.Equal(t, "Secret \"dummy\" was generated.\nConfigMap \"dummy\" was generated.\n", output)
+}
+
 func TestKindService_ApplyKindUpdateWithError(t *testing.T) {
 	for _, entry := range upsertTests {
 		config := loader.Config{}
 
-		kindService, _, fakeClientSet := getKindServiceInterface(config)
-		fakeClientSet.PrependReactor("get", entry.resource, testingKube.GetObjectReturnFunc(entry.object))
-		fakeClientSet.PrependReactor("update", entry.resource, testingKube.ErrorReturnFunc)
+		kindService, _, fakeClientSet := getKindService(config)
+		prependReactor(kindService, fakeClientSet, "get", entry.resource, testingKube.GetObjectReturnFunc(entry.object))
+		prependReactor(kindService, fakeClientSet, "patch", entry.resource, testingKube.ErrorReturnFunc)
+
+		expectedError := strings.TrimSuffix(entry.out, " was updated.\n") + " could not be applied: explode"
 
-		assert.EqualError(t, kindService.ApplyKind("foobar", []string{entry.kind}, "foobar"), "explode", fmt.Sprintf("Test failed for resource %s", entry.resource))
+		assert.EqualError(t, kindService.ApplyKind("foobar", []string{entry.kind}, "foobar"), expectedError, fmt.Sprintf("Test failed for resource %s", entry.resource))
 	}
 }
 
@@ -295,9 +727,9 @@ func TestKindService_ApplyKindUpdate(t *testing.T) {
 	for _, entry := range upsertTests {
 		config := loader.Config{}
 
-		kindService, _, fakeClientSet := getKindServiceInterface(config)
-		fakeClientSet.PrependReactor("get", entry.resource, testingKube.GetObjectReturnFunc(entry.object))
-		fakeClientSet.PrependReactor("update", entry.resource, testingKube.NilReturnFunc)
+		kindService, _, fakeClientSet := getKindService(config)
+		prependReactor(kindService, fakeClientSet, "get", entry.resource, testingKube.GetObjectReturnFunc(entry.object))
+		prependReactor(kindService, fakeClientSet, "patch", entry.resource, testingKube.NilReturnFunc)
 
 		output := captureOutput(func() {
 			assert.NoError(t, kindService.ApplyKind("foobar", []string{entry.kind}, "foobar"), fmt.Sprintf("Test failed for resource %s", entry.resource))
@@ -307,13 +739,262 @@ func TestKindService_ApplyKindUpdate(t *testing.T) {
 	}
 }
 
+func TestKindService_ApplyKindUpdateStatefulSetKeepsImmutableFields(t *testing.T) {
+	kindService, _, fakeClientSet := getKindServiceInterface(loader.Config{})
+
+	existing := &apps.StatefulSet{
+		ObjectMeta: meta.ObjectMeta{Name: "dummy"},
+		Spec: apps.StatefulSetSpec{
+			ServiceName:          "existing",
+			Selector:             &meta.LabelSelector{MatchLabels: map[string]string{"app": "existing"}},
+			VolumeClaimTemplates: []coreV1.PersistentVolumeClaim{{ObjectMeta: meta.ObjectMeta{Name: "data"}}},
+		},
+	}
+
+	var patch string
+
+	fakeClientSet.PrependReactor("get", "statefulsets", testingKube.GetObjectReturnFunc(existing))
+	fakeClientSet.PrependReactor("patch", "statefulsets", func(action testingK8s.Action) (bool, runtime.Object, error) {
+		patch = string(action.(testingK8s.PatchAction).GetPatch())
+
+		return true, nil, nil
+	})
+
+	var kind = `kind: StatefulSet
+apiVersion: apps/v1
+metadata:
+  name: dummy
+spec:
+  serviceName: changed
+  replicas: 3
+  selector:
+    matchLabels:
+      app: changed`
+
+	captureOutput(func() {
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{kind}, "foobar"))
+	})
+
+	var patchContent map[string]interface{}
+
+	assert.NoError(t, json.Unmarshal([]byte(patch), &patchContent))
+	assert.Equal(t, map[string]interface{}{"replicas": float64(3)}, patchContent["spec"])
+}
+
+func TestKindService_ApplyKindJobWithUnchangedSpec(t *testing.T) {
+	kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+	objects, err := kindService.RenderKind("foobar", []string{job}, "foobar")
+	assert.NoError(t, err)
+
+	specHash, err := getSpecHash(objects[0].(*batchV1.Job).Spec)
+	assert.NoError(t, err)
+
+	existingJob := &batchV1.Job{ObjectMeta: meta.ObjectMeta{Name: "dummy", Annotations: map[string]string{specHashAnnotation: specHash}}}
+	fakeClientSet.PrependReactor("get", "jobs", testingKube.GetObjectReturnFunc(existingJob))
+
+	output := captureOutput(func() {
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{job}, "foobar"))
+	})
+
+	assert.Equal(t, "Job \"dummy\" is unchanged.\n", output)
+	assert.Equal(t, []string{"dummy"}, kindService.usedKind.job)
+	assert.Len(t, fakeClientSet.Actions(), 1)
+}
+
+func TestKindService_ApplyKindJobWithChangedSpec(t *testing.T) {
+	kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+	existingJob := &batchV1.Job{ObjectMeta: meta.ObjectMeta{Name: "dummy", Annotations: map[string]string{specHashAnnotation: "old"}}}
+	fakeClientSet.PrependReactor("get", "jobs", testingKube.GetObjectReturnFunc(existingJob))
+	fakeClientSet.PrependReactor("delete", "jobs", testingKube.NilReturnFunc)
+	fakeClientSet.PrependReactor("create", "jobs", testingKube.NilReturnFunc)
+
+	output := captureOutput(func() {
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{job}, "foobar"))
+	})
+
+	assert.Equal(t, "Job \"dummy\" was recreated.\n", output)
+
+	actions := fakeClientSet.Actions()
+	assert.Len(t, actions, 3)
+	assert.Equal(t, "delete", actions[1].GetVerb())
+
+	createdJob := actions[2].(testingK8s.CreateAction).GetObject().(*batchV1.Job)
+	assert.NotEqual(t, "old", createdJob.Annotations[specHashAnnotation])
+}
+
+func TestKindService_ApplyKindJobWithErrorOnDelete(t *testing.T) {
+	kindService, _, fakeClientSet := getKindServiceInterface(loader.Config{})
+
+	fakeClientSet.PrependReactor("get", "jobs", testingKube.GetObjectReturnFunc(&batchV1.Job{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}))
+	fakeClientSet.PrependReactor("delete", "jobs", testingKube.ErrorReturnFunc)
+
+	assert.EqualError(t, kindService.ApplyKind("foobar", []string{job}, "foobar"), "explode")
+}
+
+func TestKindService_ApplyKindUpdateDeploymentWithAutoscaler(t *testing.T) {
+	var existingReplicas int32 = 4
+
+	for _, entry := range []struct {
+		target          string
+		patchesReplicas bool
+	}{
+		{"dummy", false},
+		{"other", true},
+	} {
+		kindService, _, fakeClientSet := getKindServiceInterface(loader.Config{})
+
+		existing := &apps.Deployment{ObjectMeta: meta.ObjectMeta{Name: "dummy"}, Spec: apps.DeploymentSpec{Replicas: &existingReplicas}}
+		autoscalers := &autoscalingV1.HorizontalPodAutoscalerList{Items: []autoscalingV1.HorizontalPodAutoscaler{
+			{Spec: autoscalingV1.HorizontalPodAutoscalerSpec{ScaleTargetRef: autoscalingV1.CrossVersionObjectReference{Kind: "Deployment", Name: entry.target}}},
+		}}
+
+		var patch string
+
+		fakeClientSet.PrependReactor("get", "deployments", testingKube.GetObjectReturnFunc(existing))
+		fakeClientSet.PrependReactor("list", "horizontalpodautoscalers", testingKube.GetObjectReturnFunc(autoscalers))
+		fakeClientSet.PrependReactor("patch", "deployments", func(action testingK8s.Action) (bool, runtime.Object, error) {
+			patch = string(action.(testingK8s.PatchAction).GetPatch())
+
+			return true, nil, nil
+		})
+
+		captureOutput(func() {
+			assert.NoError(t, kindService.ApplyKind("foobar", []string{deployment + "\nspec:\n  replicas: 1"}, "foobar"))
+		})
+
+		assert.Equal(t, entry.patchesReplicas, strings.Contains(patch, `"replicas":1`), fmt.Sprintf("Test failed for autoscaler target %s", entry.target))
+	}
+}
+
+func TestKindService_ApplyKindUpdateDeploymentWithErrorForAutoscalers(t *testing.T) {
+	kindService, _, fakeClientSet := getKindServiceInterface(loader.Config{})
+
+	fakeClientSet.PrependReactor("get", "deployments", testingKube.GetObjectReturnFunc(&apps.Deployment{ObjectMeta: meta.ObjectMeta{Name: "dummy"}}))
+	fakeClientSet.PrependReactor("list", "horizontalpodautoscalers", testingKube.ErrorReturnFunc)
+
+	assert.EqualError(t, kindService.ApplyKind("foobar", []string{deployment}, "foobar"), "explode")
+}
+
+func TestKindService_ApplyKindPodDisruptionBudget(t *testing.T) {
+	object, _, err := scheme.Codecs.UniversalDeserializer().Decode([]byte(podDisruptionBudget), nil, nil)
+	assert.NoError(t, err)
+
+	specHash, err := getSpecHash(object.(*policy.PodDisruptionBudget).Spec)
+	assert.NoError(t, err)
+
+	for _, entry := range []struct {
+		hash    string
+		out     string
+		actions []string
+	}{
+		{specHash, "PodDisruptionBudget \"dummy\" was updated.\n", []string{"get", "patch"}},
+		{"old", "PodDisruptionBudget \"dummy\" was recreated.\n", []string{"get", "delete", "create"}},
+	} {
+		kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+		existingBudget := &policy.PodDisruptionBudget{ObjectMeta: meta.ObjectMeta{Name: "dummy", Annotations: map[string]string{specHashAnnotation: entry.hash}}}
+		prependReactor(kindService, fakeClientSet, "*", "poddisruptionbudgets", testingKube.NilReturnFunc)
+		prependReactor(kindService, fakeClientSet, "get", "poddisruptionbudgets", testingKube.GetObjectReturnFunc(existingBudget))
+
+		output := captureOutput(func() {
+			assert.NoError(t, kindService.ApplyKind("foobar", []string{podDisruptionBudget}, "foobar"))
+		})
+
+		assert.Equal(t, entry.out, output)
+		assert.Equal(t, entry.actions, getDynamicVerbs(kindService))
+		assert.Equal(t, []string{"dummy"}, kindService.usedKind.generic["poddisruptionbudgets"])
+	}
+}
+
+func TestKindService_ApplyKindRoleBindingWithChangedRole(t *testing.T) {
+	for _, entry := range []struct {
+		resource string
+		kind     string
+		roleKind string
+		out      string
+	}{
+		{"rolebindings", roleBinding, "Role", "RoleBinding \"dummy\" was recreated.\n"},
+		{"clusterrolebindings", clusterRoleBinding, "ClusterRole", "ClusterRoleBinding \"dummy\" was recreated.\n"},
+	} {
+		kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+		existingObject := &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "dummy"},
+			"roleRef":  map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": entry.roleKind, "name": "old"},
+		}}
+		prependReactor(kindService, fakeClientSet, "*", entry.resource, testingKube.NilReturnFunc)
+		prependReactor(kindService, fakeClientSet, "get", entry.resource, testingKube.GetObjectReturnFunc(existingObject))
+
+		output := captureOutput(func() {
+			assert.NoError(t, kindService.ApplyKind("foobar", []string{entry.kind}, "foobar"), fmt.Sprintf("Test failed for resource %s", entry.resource))
+		})
+
+		assert.Equal(t, entry.out, output)
+		assert.Equal(t, []string{"get", "delete", "create"}, getDynamicVerbs(kindService), fmt.Sprintf("Test failed for resource %s", entry.resource))
+	}
+}
+
+func TestKindService_ApplyKindRoleBindingWithSameRole(t *testing.T) {
+	kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+	existingObject := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "dummy"},
+		"roleRef":  map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": "Role", "name": "dummy"},
+	}}
+	prependReactor(kindService, fakeClientSet, "*", "rolebindings", testingKube.NilReturnFunc)
+	prependReactor(kindService, fakeClientSet, "get", "rolebindings", testingKube.GetObjectReturnFunc(existingObject))
+
+	output := captureOutput(func() {
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{roleBinding}, "foobar"))
+	})
+
+	assert.Equal(t, "RoleBinding \"dummy\" was updated.\n", output)
+	assert.Equal(t, []string{"get", "patch"}, getDynamicVerbs(kindService))
+}
+
+func TestKindService_ApplyKindRoleBindingWithErrorForDelete(t *testing.T) {
+	kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+	existingObject := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "dummy"},
+		"roleRef":  map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": "Role", "name": "old"},
+	}}
+	prependReactor(kindService, fakeClientSet, "delete", "rolebindings", testingKube.ErrorReturnFunc)
+	prependReactor(kindService, fakeClientSet, "get", "rolebindings", testingKube.GetObjectReturnFunc(existingObject))
+
+	assert.EqualError(t, kindService.ApplyKind("foobar", []string{roleBinding}, "foobar"), "explode")
+}
+
+func TestKindService_ApplyKindServiceAccountKeepsToken(t *testing.T) {
+	kindService, _, fakeClientSet := getKindService(loader.Config{})
+
+	existingServiceAccount := &coreV1.ServiceAccount{ObjectMeta: meta.ObjectMeta{Name: "dummy"}, Secrets: []coreV1.ObjectReference{{Name: "dummy-token-abc"}}}
+
+	var patch string
+
+	prependReactor(kindService, fakeClientSet, "get", "serviceaccounts", testingKube.GetObjectReturnFunc(existingServiceAccount))
+	prependReactor(kindService, fakeClientSet, "patch", "serviceaccounts", func(action testingK8s.Action) (bool, runtime.Object, error) {
+		patch = string(action.(testingK8s.PatchAction).GetPatch())
+
+		return true, nil, nil
+	})
+
+	captureOutput(func() {
+		assert.NoError(t, kindService.ApplyKind("foobar", []string{serviceAccount}, "foobar"))
+	})
+
+	assert.NotContains(t, patch, "secrets")
+}
+
 func TestKindService_ApplyKindUpdateWithContainers(t *testing.T) {
 	for _, entry := range setImageTests {
 		config := loader.Config{}
 
-		kindService, imageServiceMock, fakeClientSet := getKindServiceInterface(config)
-		fakeClientSet.PrependReactor("get", entry.resource, testingKube.GetObjectReturnFunc(entry.object))
-		fakeClientSet.PrependReactor("update", entry.resource, testingKube.NilReturnFunc)
+		kindService, imageServiceMock, fakeClientSet := getKindService(config)
+		prependReactor(kindService, fakeClientSet, "get", entry.resource, testingKube.GetObjectReturnFunc(entry.object))
+		prependReactor(kindService, fakeClientSet, "patch", entry.resource, testingKube.NilReturnFunc)
 
 		imageServiceMock.On("List", loader.Cleanup{ImagePath: "eu.gcr.io/foobar/app"}).Return(new(model.TagCollection), nil)
 
@@ -371,30 +1052,158 @@ func TestKindService_SetImageForContainer(t *testing.T) {
 
 }
 
+// prependReactor adds the reaction to the typed and the dynamic client, objects of the dynamic client are converted to unstructured
+func prependReactor(kindService *kindService, fakeClientSet *fake.Clientset, verb string, resource string, reaction testingK8s.ReactionFunc) {
+	fakeClientSet.PrependReactor(verb, resource, reaction)
+
+	kindService.dynamicClientPool.(*dynamicFake.FakeClientPool).PrependReactor(verb, resource, func(action testingK8s.Action) (bool, runtime.Object, error) {
+		handled, object, err := reaction(action)
+
+		if object == nil {
+			return handled, nil, err
+		}
+
+		content, convertErr := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+
+		if convertErr != nil {
+			return true, nil, convertErr
+		}
+
+		return handled, &unstructured.Unstructured{Object: content}, err
+	})
+}
+
 func getKindServiceInterface(config loader.Config) (KindInterface, *mocks.ImagesInterface, *fake.Clientset) {
 	imageServiceMock := new(mocks.ImagesInterface)
 
-	fakeClientSet := fake.NewSimpleClientset()
+	fakeClientSet := getFakeClientSet()
 
-	return NewKind(fakeClientSet, imageServiceMock, config), imageServiceMock, fakeClientSet
+	return NewKind(fakeClientSet, new(dynamicFake.FakeClientPool), imageServiceMock, config), imageServiceMock, fakeClientSet
 }
 
 func getKindService(config loader.Config) (*kindService, *mocks.ImagesInterface, *fake.Clientset) {
 
 	imageServiceMock := new(mocks.ImagesInterface)
 
-	fakeClientSet := fake.NewSimpleClientset()
+	fakeClientSet := getFakeClientSet()
 
 	k := new(kindService)
 	k.clientSet = fakeClientSet
+	k.dynamicClientPool = new(dynamicFake.FakeClientPool)
 	k.imagesService = imageServiceMock
 	k.config = config
-	k.usedKind = usedKind{}
+	k.usedKind = usedKind{generic: map[string][]string{}}
+	k.apiResources = map[schema.GroupVersionKind]*meta.APIResource{}
 	k.decoder = scheme.Codecs.UniversalDeserializer()
 
 	return k, imageServiceMock, fakeClientSet
 }
 
+// getFakeClientSet returns a client set which knows the kinds of the tests without pods and a custom resource for the discovery
+func getFakeClientSet() *fake.Clientset {
+	fakeClientSet := fake.NewSimpleClientset()
+
+	verbs := meta.Verbs{"get", "list", "create", "update", "patch", "delete"}
+
+	fakeClientSet.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*meta.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []meta.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: verbs},
+				{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: verbs},
+				{Name: "serviceaccounts", Kind: "ServiceAccount", Namespaced: true, Verbs: verbs},
+				{Name: "persistentvolumes", Kind: "PersistentVolume", Namespaced: false, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []meta.APIResource{
+				{Name: "daemonsets", Kind: "DaemonSet", Namespaced: true, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "batch/v1beta1",
+			APIResources: []meta.APIResource{
+				{Name: "cronjobs", Kind: "CronJob", Namespaced: true, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "extensions/v1beta1",
+			APIResources: []meta.APIResource{
+				{Name: "ingresses", Kind: "Ingress", Namespaced: true, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "autoscaling/v1",
+			APIResources: []meta.APIResource{
+				{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler", Namespaced: true, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "autoscaling/v2beta1",
+			APIResources: []meta.APIResource{
+				{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler", Namespaced: true, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "policy/v1beta1",
+			APIResources: []meta.APIResource{
+				{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget", Namespaced: true, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "networking.k8s.io/v1",
+			APIResources: []meta.APIResource{
+				{Name: "networkpolicies", Kind: "NetworkPolicy", Namespaced: true, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []meta.APIResource{
+				{Name: "roles", Kind: "Role", Namespaced: true, Verbs: verbs},
+				{Name: "rolebindings", Kind: "RoleBinding", Namespaced: true, Verbs: verbs},
+				{Name: "clusterroles", Kind: "ClusterRole", Namespaced: false, Verbs: verbs},
+				{Name: "clusterrolebindings", Kind: "ClusterRoleBinding", Namespaced: false, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "certmanager.k8s.io/v1alpha1",
+			APIResources: []meta.APIResource{
+				{Name: "certificates", Kind: "Certificate", Namespaced: true, Verbs: verbs},
+				{Name: "certificates/status", Kind: "Certificate", Namespaced: true, Verbs: meta.Verbs{"get", "update"}},
+			},
+		},
+	}
+
+	return fakeClientSet
+}
+
+// addAliasGroups serves daemon sets and network policies also under the extensions group, like older servers do
+func addAliasGroups(fakeClientSet *fake.Clientset) {
+	verbs := meta.Verbs{"get", "list", "create", "update", "patch", "delete"}
+
+	fakeDiscovery := fakeClientSet.Discovery().(*fakediscovery.FakeDiscovery)
+	fakeDiscovery.Resources = append([]*meta.APIResourceList{
+		{
+			GroupVersion: "extensions/v1beta1",
+			APIResources: []meta.APIResource{
+				{Name: "daemonsets", Kind: "DaemonSet", Namespaced: true, Verbs: verbs},
+				{Name: "networkpolicies", Kind: "NetworkPolicy", Namespaced: true, Verbs: verbs},
+			},
+		},
+	}, fakeDiscovery.Resources...)
+}
+
+func getDynamicVerbs(kindService *kindService) []string {
+	var verbs []string
+
+	for _, action := range kindService.dynamicClientPool.(*dynamicFake.FakeClientPool).Actions() {
+		verbs = append(verbs, action.GetVerb())
+	}
+
+	return verbs
+}
+
 func captureOutput(f func()) string {
 	oldWriter := writer
 	var buf bytes.Buffer
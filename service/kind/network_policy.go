@@ -0,0 +1,43 @@
+package kind
+
+import (
+	"kube-helper/loader"
+
+	networking "k8s.io/api/networking/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultNetworkPolicyLabel marks the network policy which isolates a new namespace,
+// the policy is removed by the cleanup as soon as the manifests define their own network policies
+const DefaultNetworkPolicyLabel = "kube-helper/default-policy"
+
+const defaultNetworkPolicyName = "default-isolation"
+
+// NewDefaultNetworkPolicy returns a policy which denies all incoming traffic of the namespace,
+// except the traffic from the namespace itself and the allowed namespaces and cidrs of the config
+func NewDefaultNetworkPolicy(kubernetesNamespace string, config loader.NetworkPolicy) *networking.NetworkPolicy {
+	peers := []networking.NetworkPolicyPeer{
+		{PodSelector: &metaV1.LabelSelector{}},
+	}
+
+	for _, labels := range config.AllowedNamespaceLabels {
+		peers = append(peers, networking.NetworkPolicyPeer{NamespaceSelector: &metaV1.LabelSelector{MatchLabels: labels}})
+	}
+
+	for _, cidr := range config.AllowedCIDRs {
+		peers = append(peers, networking.NetworkPolicyPeer{IPBlock: &networking.IPBlock{CIDR: cidr}})
+	}
+
+	return &networking.NetworkPolicy{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      defaultNetworkPolicyName,
+			Namespace: kubernetesNamespace,
+			Labels:    map[string]string{DefaultNetworkPolicyLabel: "true"},
+		},
+		Spec: networking.NetworkPolicySpec{
+			PodSelector: metaV1.LabelSelector{},
+			Ingress:     []networking.NetworkPolicyIngressRule{{From: peers}},
+			PolicyTypes: []networking.PolicyType{networking.PolicyTypeIngress},
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package kind
+
+import (
+	"testing"
+
+	"kube-helper/loader"
+
+	"github.com/stretchr/testify/assert"
+	networking "k8s.io/api/networking/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewDefaultNetworkPolicy(t *testing.T) {
+	networkPolicy := NewDefaultNetworkPolicy("app-foobar", loader.NetworkPolicy{
+		Isolate:                true,
+		AllowedNamespaceLabels: []map[string]string{{"name": "kube-system"}},
+		AllowedCIDRs:           []string{"130.211.0.0/22"},
+	})
+
+	assert.Equal(t, "default-isolation", networkPolicy.Name)
+	assert.Equal(t, "app-foobar", networkPolicy.Namespace)
+	assert.Equal(t, map[string]string{DefaultNetworkPolicyLabel: "true"}, networkPolicy.Labels)
+	assert.Equal(t, []networking.PolicyType{networking.PolicyTypeIngress}, networkPolicy.Spec.PolicyTypes)
+	assert.Equal(t, metaV1.LabelSelector{}, networkPolicy.Spec.PodSelector)
+	assert.Equal(t, []networking.NetworkPolicyIngressRule{{From: []networking.NetworkPolicyPeer{
+		{PodSelector: &metaV1.LabelSelector{}},
+		{NamespaceSelector: &metaV1.LabelSelector{MatchLabels: map[string]string{"name": "kube-system"}}},
+		{IPBlock: &networking.IPBlock{CIDR: "130.211.0.0/22"}},
+	}}}, networkPolicy.Spec.Ingress)
+}
@@ -0,0 +1,117 @@
+package kind
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// LastAppliedAnnotation holds the configuration of the last apply, it is the base for the merge patch of the next apply
+const LastAppliedAnnotation = "kube-helper/last-applied-configuration"
+
+// setLastAppliedAnnotation stores the configuration of the object without the annotation itself in the annotation
+func setLastAppliedAnnotation(object runtime.Object) error {
+	accessor, err := meta.Accessor(object)
+
+	if err != nil {
+		return err
+	}
+
+	annotations := accessor.GetAnnotations()
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	delete(annotations, LastAppliedAnnotation)
+	accessor.SetAnnotations(annotations)
+
+	configuration, err := getConfiguration(object)
+
+	if err != nil {
+		return err
+	}
+
+	annotations[LastAppliedAnnotation] = string(configuration)
+	accessor.SetAnnotations(annotations)
+
+	return nil
+}
+
+// getConfiguration returns the object as json without the fields which are only set by the server
+func getConfiguration(object runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+
+	return json.Marshal(content)
+}
+
+// getApplyPatch returns a three-way merge patch between the last applied configuration, the object and the existing object,
+// fields which were never applied by kube-helper stay untouched. Kinds without a go type get a json merge patch.
+func getApplyPatch(object runtime.Object, existingObject runtime.Object, dataStruct interface{}) ([]byte, error) {
+	err := setLastAppliedAnnotation(object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existingAccessor, err := meta.Accessor(existingObject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var original []byte
+
+	if lastApplied, ok := existingAccessor.GetAnnotations()[LastAppliedAnnotation]; ok {
+		original = []byte(lastApplied)
+	}
+
+	modified, err := getConfiguration(object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the typed clients return objects without the type information, it is taken from the applied object
+	existingObject.GetObjectKind().SetGroupVersionKind(object.GetObjectKind().GroupVersionKind())
+
+	current, err := json.Marshal(existingObject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if dataStruct == nil {
+		return jsonmergepatch.CreateThreeWayJSONMergePatch(original, modified, current)
+	}
+
+	patchMeta, err := strategicpatch.NewPatchMetaFromStruct(dataStruct)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return strategicpatch.CreateThreeWayMergePatch(original, modified, current, patchMeta, true)
+}
+
+// getApplyError names the object which could not be patched, a conflict means the object was changed during the apply
+func getApplyError(kind string, name string, err error) error {
+	if apiErrors.IsConflict(err) {
+		return fmt.Errorf("%s \"%s\" was changed by someone else during the apply, please try again: %s", kind, name, err)
+	}
+
+	return fmt.Errorf("%s \"%s\" could not be applied: %s", kind, name, err)
+}
@@ -0,0 +1,65 @@
+package kind
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSetLastAppliedAnnotation(t *testing.T) {
+	configMap := &coreV1.ConfigMap{
+		TypeMeta:   metaV1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metaV1.ObjectMeta{Name: "dummy", Annotations: map[string]string{LastAppliedAnnotation: "old"}},
+		Data:       map[string]string{"a": "1"},
+	}
+
+	assert.NoError(t, setLastAppliedAnnotation(configMap))
+	assert.Equal(t, `{"apiVersion":"v1","data":{"a":"1"},"kind":"ConfigMap","metadata":{"name":"dummy"}}`, configMap.Annotations[LastAppliedAnnotation])
+}
+
+func TestGetApplyPatch(t *testing.T) {
+	for _, entry := range []struct {
+		lastApplied string
+		data        map[string]interface{}
+	}{
+		{`{"data":{"a":"1","b":"2"}}`, map[string]interface{}{"a": "3", "b": nil}},
+		{"", map[string]interface{}{"a": "3"}},
+	} {
+		existingConfigMap := &coreV1.ConfigMap{
+			ObjectMeta: metaV1.ObjectMeta{Name: "dummy", Annotations: map[string]string{}},
+			Data:       map[string]string{"a": "1", "b": "2", "c": "set by someone else"},
+		}
+
+		if entry.lastApplied != "" {
+			existingConfigMap.Annotations[LastAppliedAnnotation] = entry.lastApplied
+		}
+
+		configMap := &coreV1.ConfigMap{ObjectMeta: metaV1.ObjectMeta{Name: "dummy"}, Data: map[string]string{"a": "3"}}
+
+		patch, err := getApplyPatch(configMap, existingConfigMap, coreV1.ConfigMap{})
+
+		assert.NoError(t, err)
+
+		var patchContent struct {
+			Data     map[string]interface{} `json:"data"`
+			Metadata metaV1.ObjectMeta      `json:"metadata"`
+		}
+
+		assert.NoError(t, json.Unmarshal(patch, &patchContent))
+		assert.Equal(t, entry.data, patchContent.Data, "Test failed for last applied configuration "+entry.lastApplied)
+		assert.Equal(t, configMap.Annotations[LastAppliedAnnotation], patchContent.Metadata.Annotations[LastAppliedAnnotation])
+	}
+}
+
+func TestGetApplyError(t *testing.T) {
+	conflict := apiErrors.NewConflict(schema.GroupResource{Resource: "deployments"}, "dummy", errors.New("the object has been modified"))
+
+	assert.EqualError(t, getApplyError("Deployment", "dummy", conflict), "Deployment \"dummy\" was changed by someone else during the apply, please try again: "+conflict.Error())
+	assert.EqualError(t, getApplyError("Deployment", "dummy", errors.New("explode")), "Deployment \"dummy\" could not be applied: explode")
+}
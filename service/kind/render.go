@@ -0,0 +1,142 @@
+package kind
+
+import (
+	"fmt"
+	"strings"
+
+	apps "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
+	batch "k8s.io/api/batch/v1beta1"
+	coreV1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RenderKind decodes a document and applies the same changes like ApplyKind, e.g. the images and the namespace,
+// but returns the objects instead of sending them to the cluster. The document can be yaml or json,
+// the items of a List are returned as separate objects and kinds without special handling are returned unstructured
+func (k *kindService) RenderKind(kubernetesNamespace string, fileLines []string, namespaceWithoutPrefix string) ([]runtime.Object, error) {
+	return k.renderDocument([]byte(strings.Join(fileLines, "\n")), kubernetesNamespace, namespaceWithoutPrefix)
+}
+
+func (k *kindService) renderDocument(data []byte, kubernetesNamespace string, namespaceWithoutPrefix string) ([]runtime.Object, error) {
+
+	object, groupVersionKind, err := k.decoder.Decode(data, nil, nil)
+
+	if runtime.IsNotRegisteredError(err) {
+		// kinds which are unknown to the client, e.g. custom resources
+		unstructuredObject, err := decodeUnstructured(data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		object, err := k.renderUnstructured(unstructuredObject, kubernetesNamespace)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []runtime.Object{object}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	list, isList := object.(*coreV1.List)
+
+	if !isList {
+		object, err = k.renderObject(object, groupVersionKind, kubernetesNamespace, namespaceWithoutPrefix)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []runtime.Object{object}, nil
+	}
+
+	objects := []runtime.Object{}
+
+	for index, item := range list.Items {
+		itemObjects, err := k.renderDocument(item.Raw, kubernetesNamespace, namespaceWithoutPrefix)
+
+		if err != nil {
+			return nil, fmt.Errorf("item %d of the list: %s", index, err)
+		}
+
+		objects = append(objects, itemObjects...)
+	}
+
+	return objects, nil
+}
+
+func (k *kindService) renderObject(object runtime.Object, groupVersionKind *schema.GroupVersionKind, kubernetesNamespace string, namespaceWithoutPrefix string) (runtime.Object, error) {
+	var err error
+
+	switch object := object.(type) {
+	case *coreV1.Service, *coreV1.PersistentVolumeClaim:
+	case *apps.Deployment:
+		err = k.setImageForContainer(object.Annotations, object.Spec.Template.Spec.Containers, namespaceWithoutPrefix)
+	case *apps.StatefulSet:
+		err = k.setImageForContainer(object.Annotations, object.Spec.Template.Spec.Containers, namespaceWithoutPrefix)
+	case *batchV1.Job:
+		err = k.setImageForContainer(object.Annotations, object.Spec.Template.Spec.Containers, namespaceWithoutPrefix)
+	default:
+		return k.renderGenericObject(object, groupVersionKind, kubernetesNamespace, namespaceWithoutPrefix)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	accessor, err := meta.Accessor(object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	accessor.SetNamespace(kubernetesNamespace)
+	object.GetObjectKind().SetGroupVersionKind(*groupVersionKind)
+
+	return object, nil
+}
+
+// renderGenericObject prepares the kinds without special handling of the apply, they are applied with the dynamic client
+func (k *kindService) renderGenericObject(object runtime.Object, groupVersionKind *schema.GroupVersionKind, kubernetesNamespace string, namespaceWithoutPrefix string) (runtime.Object, error) {
+	var err error
+
+	switch object := object.(type) {
+	case *apps.DaemonSet:
+		err = k.setImageForContainer(object.Annotations, object.Spec.Template.Spec.Containers, namespaceWithoutPrefix)
+	case *batch.CronJob:
+		err = k.setImageForContainer(object.Annotations, object.Spec.JobTemplate.Spec.Template.Spec.Containers, namespaceWithoutPrefix)
+	case *rbac.RoleBinding:
+		setNamespaceForServiceAccounts(object.Subjects, kubernetesNamespace)
+	case *rbac.ClusterRoleBinding:
+		setNamespaceForServiceAccounts(object.Subjects, kubernetesNamespace)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	unstructuredObject, err := convertToUnstructured(object, groupVersionKind)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return k.renderUnstructured(unstructuredObject, kubernetesNamespace)
+}
+
+// setNamespaceForServiceAccounts binds the service accounts without a namespace to the namespace of the application
+func setNamespaceForServiceAccounts(subjects []rbac.Subject, kubernetesNamespace string) {
+	for idx, subject := range subjects {
+		if subject.Kind == rbac.ServiceAccountKind && subject.Namespace == "" {
+			subjects[idx].Namespace = kubernetesNamespace
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package kind
+
+import (
+	"strings"
+	"testing"
+
+	"kube-helper/loader"
+	"kube-helper/model"
+
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestKindService_RenderKind(t *testing.T) {
+	kindService, imageServiceMock, fakeClientSet := getKindServiceInterface(loader.Config{})
+
+	tags := new(model.TagCollection)
+	tags.Manifests = map[string]model.Manifest{
+		"stuff": {Tags: []string{"staging-foobar-latest", "staging-foobar-3"}},
+	}
+
+	imageServiceMock.On("List", loader.Cleanup{ImagePath: "eu.gcr.io/foobar/app"}).Return(tags, nil)
+
+	objects, err := kindService.RenderKind("dummy-foobar", []string{deploymentWithAnnotation}, "foobar")
+
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+
+	deployment := objects[0].(*apps.Deployment)
+
+	assert.Equal(t, "dummy-foobar", deployment.Namespace)
+	assert.Equal(t, "eu.gcr.io/foobar/app:staging-foobar-3", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, "Deployment", deployment.Kind)
+	assert.Equal(t, "apps/v1", deployment.APIVersion)
+	assert.Empty(t, fakeClientSet.Actions())
+}
+
+func TestKindService_RenderKindWithClusterScopedKind(t *testing.T) {
+	kindService, _, _ := getKindServiceInterface(loader.Config{})
+
+	objects, err := kindService.RenderKind("dummy-foobar", []string{persistentVolume}, "foobar")
+
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+
+	persistentVolume := objects[0].(*unstructured.Unstructured)
+
+	assert.Empty(t, persistentVolume.GetNamespace())
+	assert.Equal(t, "PersistentVolume", persistentVolume.GetKind())
+}
+
+func TestKindService_RenderKindWithInvalidKind(t *testing.T) {
+	kindService, _, _ := getKindServiceInterface(loader.Config{})
+
+	var kind = `kind: Pod
+apiVersion: v1
+metadata:
+  name: dummy`
+
+	objects, err := kindService.RenderKind("foobar", []string{kind}, "foobar")
+
+	assert.EqualError(t, err, "kind Pod is not supported")
+	assert.Nil(t, objects)
+}
+
+func TestKindService_RenderKindWithCustomResource(t *testing.T) {
+	kindService, _, _ := getKindServiceInterface(loader.Config{})
+
+	objects, err := kindService.RenderKind("foobar", []string{certificate}, "foobar")
+
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+
+	object := objects[0].(*unstructured.Unstructured)
+
+	assert.Equal(t, "foobar", object.GetNamespace())
+	assert.Equal(t, "true", object.GetLabels()[ManagedLabel])
+	assert.Equal(t, "dummy-tls", object.Object["spec"].(map[string]interface{})["secretName"])
+}
+
+func TestKindService_RenderKindOfflineWithUnknownKind(t *testing.T) {
+	kindService := NewOfflineKind(nil, loader.Config{}, false)
+
+	var kind = `kind: Deploymnet
+apiVersion: apps/v1
+metadata:
+  name: dummy`
+
+	objects, err := kindService.RenderKind("foobar", []string{kind}, "foobar")
+
+	assert.EqualError(t, err, "kind Deploymnet is not supported")
+	assert.Nil(t, objects)
+}
+
+func TestKindService_RenderKindOfflineWithAllowedUnknownKind(t *testing.T) {
+	kindService := NewOfflineKind(nil, loader.Config{}, true)
+
+	var kind = `kind: Unknown
+apiVersion: example.com/v1
+metadata:
+  name: dummy`
+
+	objects, err := kindService.RenderKind("foobar", []string{kind}, "foobar")
+
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "foobar", objects[0].(*unstructured.Unstructured).GetNamespace())
+}
+
+func TestKindService_RenderKindOfflineWithScopeFromScheme(t *testing.T) {
+	kindService := NewOfflineKind(nil, loader.Config{}, false)
+
+	var list = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: PersistentVolume
+  metadata:
+    name: volume
+- apiVersion: rbac.authorization.k8s.io/v1
+  kind: ClusterRole
+  metadata:
+    name: role
+- apiVersion: rbac.authorization.k8s.io/v1
+  kind: ClusterRoleBinding
+  metadata:
+    name: binding
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: config`
+
+	objects, err := kindService.RenderKind("foobar", []string{list}, "foobar")
+
+	assert.NoError(t, err)
+	assert.Len(t, objects, 4)
+
+	for _, object := range objects[:3] {
+		assert.Empty(t, object.(*unstructured.Unstructured).GetNamespace())
+	}
+
+	assert.Equal(t, "foobar", objects[3].(*unstructured.Unstructured).GetNamespace())
+}
+
+func TestKindService_RenderKindWithList(t *testing.T) {
+	kindService, _, _ := getKindServiceInterface(loader.Config{})
+
+	var list = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: first
+    namespace: default
+- apiVersion: v1
+  kind: List
+  items:
+  - apiVersion: v1
+    kind: Secret
+    metadata:
+      name: second`
+
+	objects, err := kindService.RenderKind("dummy-foobar", []string{list}, "foobar")
+
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	configMap := objects[0].(*unstructured.Unstructured)
+	secret := objects[1].(*unstructured.Unstructured)
+
+	assert.Equal(t, "first", configMap.GetName())
+	assert.Equal(t, "dummy-foobar", configMap.GetNamespace())
+	assert.Equal(t, "ConfigMap", configMap.GetKind())
+	assert.Equal(t, "second", secret.GetName())
+	assert.Equal(t, "dummy-foobar", secret.GetNamespace())
+}
+
+func TestKindService_RenderKindWithInvalidKindInList(t *testing.T) {
+	kindService, _, _ := getKindServiceInterface(loader.Config{})
+
+	var list = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: first
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: dummy`
+
+	objects, err := kindService.RenderKind("dummy-foobar", []string{list}, "foobar")
+
+	assert.EqualError(t, err, "item 1 of the list: kind Pod is not supported")
+	assert.Nil(t, objects)
+}
+
+func TestKindService_RenderKindWithJson(t *testing.T) {
+	kindService, _, _ := getKindServiceInterface(loader.Config{})
+
+	var configMap = `{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "config"
+  },
+  "data": {
+    "key": "value"
+  }
+}`
+
+	objects, err := kindService.RenderKind("dummy-foobar", strings.Split(configMap, "\n"), "foobar")
+
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+
+	renderedConfigMap := objects[0].(*unstructured.Unstructured)
+
+	assert.Equal(t, "config", renderedConfigMap.GetName())
+	assert.Equal(t, "dummy-foobar", renderedConfigMap.GetNamespace())
+	assert.Equal(t, map[string]interface{}{"key": "value"}, renderedConfigMap.Object["data"])
+}
+
+func TestKindService_RenderKindWithBindings(t *testing.T) {
+	kindService, _, _ := getKindServiceInterface(loader.Config{})
+
+	objects, err := kindService.RenderKind("dummy-foobar", []string{roleBinding}, "foobar")
+
+	assert.NoError(t, err)
+
+	renderedRoleBinding := objects[0].(*unstructured.Unstructured)
+
+	assert.Equal(t, "dummy-foobar", renderedRoleBinding.GetNamespace())
+	assert.Equal(t, "dummy-foobar", getSubjectNamespace(renderedRoleBinding))
+
+	objects, err = kindService.RenderKind("dummy-foobar", []string{clusterRoleBinding}, "foobar")
+
+	assert.NoError(t, err)
+
+	renderedClusterRoleBinding := objects[0].(*unstructured.Unstructured)
+
+	assert.Empty(t, renderedClusterRoleBinding.GetNamespace())
+	assert.Equal(t, "dummy-foobar", getSubjectNamespace(renderedClusterRoleBinding))
+	assert.Equal(t, "ClusterRoleBinding", renderedClusterRoleBinding.GetKind())
+}
+
+func TestKindService_RenderKindWithImagesForGenericKinds(t *testing.T) {
+	kindService, imageServiceMock, _ := getKindServiceInterface(loader.Config{})
+
+	tags := new(model.TagCollection)
+	tags.Manifests = map[string]model.Manifest{
+		"stuff": {Tags: []string{"staging-foobar-latest", "staging-foobar-3"}},
+	}
+
+	imageServiceMock.On("List", loader.Cleanup{ImagePath: "eu.gcr.io/foobar/app"}).Return(tags, nil)
+
+	objects, err := kindService.RenderKind("dummy-foobar", []string{daemonSetWithAnnotation}, "foobar")
+
+	assert.NoError(t, err)
+
+	containers, _ := unstructured.NestedSlice(objects[0].(*unstructured.Unstructured).Object, "spec", "template", "spec", "containers")
+
+	assert.Equal(t, "eu.gcr.io/foobar/app:staging-foobar-3", containers[0].(map[string]interface{})["image"])
+}
+
+func getSubjectNamespace(object *unstructured.Unstructured) string {
+	subjects, _ := unstructured.NestedSlice(object.Object, "subjects")
+
+	return subjects[0].(map[string]interface{})["namespace"].(string)
+}